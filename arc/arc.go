@@ -1,10 +1,16 @@
-// Package arc implement Adaptive Replacement Cache inspired by http://code.activestate.com/recipes/576532/
+// Package arc implement Adaptive Replacement Cache as described in "ARC: A Self-Tuning, Low Overhead Replacement Cache"
+// by Nimrod Megiddo and Dharmendra S. Modha https://www.usenix.org/legacy/events/fast03/tech/full_papers/megiddo/megiddo.pdf
 package arc
 
 import (
-	"github.com/FelixSeptem/collections/lfu"
-	"github.com/FelixSeptem/collections/lru"
+	"context"
+	"iter"
 	"sync"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
 )
 
 const (
@@ -12,227 +18,366 @@ const (
 	Default_ARC_Size = 1024
 )
 
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// ARC is a Cache holds arbitrary keys and values, kept for the callers written before Cache was type parameterized
+type ARC = Cache[interface{}, interface{}]
+
 // a fixed size arc(Adaptive Replacement Cache) cache
-type ARC struct {
+type Cache[K comparable, V any] struct {
 	lock     sync.RWMutex
 	capacity int
-	p        int
+	// p is the target size of t1
+	p       int
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+	keys    cache.Codec[K]
+	values  cache.Codec[V]
+
+	// t1 holds the items seen once recently and t2 the items seen at least twice, both from the least recently
+	// used at front to the most recently used at back
+	t1    *list.List[payload[K, V]]
+	t2    *list.List[payload[K, V]]
+	items map[K]*list.Element[payload[K, V]]
 
-	t1 *lru.LRU
-	b1 *lru.LRU
+	// b1 and b2 remember only the keys recently evicted from t1 and t2, in the same order
+	b1     *list.List[ghost[K]]
+	b2     *list.List[ghost[K]]
+	ghosts map[K]*list.Element[ghost[K]]
+}
 
-	t2 *lfu.LFU
-	b2 *lfu.LFU
+// payload contains the value t1 and t2 hold
+type payload[K comparable, V any] struct {
+	key      K
+	value    V
+	frequent bool
+}
+
+// ghost contains the key b1 and b2 hold
+type ghost[K comparable] struct {
+	key      K
+	frequent bool
 }
 
 // NewARC return a given size arc
 func NewARCCache(size int) *ARC {
+	return New[interface{}, interface{}](size)
+}
+
+// New return a given size arc holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
 	if size <= 0 {
 		size = Default_ARC_Size
 	}
-	return &ARC{
+	a := &Cache[K, V]{
 		capacity: size,
 		p:        0,
-		t1:       lru.NewLRUCache(size),
-		b1:       lru.NewLRUCache(size),
-		t2:       lfu.NewLFUCache(size),
-		b2:       lfu.NewLFUCache(size),
+		t1:       list.New[payload[K, V]](),
+		t2:       list.New[payload[K, V]](),
+		items:    make(map[K]*list.Element[payload[K, V]]),
+		b1:       list.New[ghost[K]](),
+		b2:       list.New[ghost[K]](),
+		ghosts:   make(map[K]*list.Element[ghost[K]]),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	if a.keys == nil {
+		a.keys = cache.GobCodec[K]{}
+	}
+	if a.values == nil {
+		a.values = cache.GobCodec[V]{}
+	}
+	return a
+}
+
+// return the ARC running information
+func (a *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := a.stats.Stats()
+	return int(s.Hits), int(s.Misses), a.capacity, a.Len()
+}
+
+// return a snapshot of the ARC statistics
+func (a *Cache[K, V]) Stats() cache.Stats {
+	return a.stats.Stats()
+}
+
+// ResetStats set all the ARC statistics to zero
+func (a *Cache[K, V]) ResetStats() {
+	a.stats.Reset()
 }
 
 // return the ARC max capacity
-func (a *ARC) Cap() int {
+func (a *Cache[K, V]) Cap() int {
 	return a.capacity
 }
 
 // Add a new item into arc
-func (a *ARC) Set(key, value interface{}) bool {
+func (a *Cache[K, V]) Set(key K, value V) (evicted bool) {
 	a.lock.Lock()
-	defer a.lock.Unlock()
-
-	if a.t1.Contains(key) {
-		a.t1.Remove(key)
-		a.t2.Set(key, value)
-		return false
-	}
+	defer a.evicted.Unlock(&a.lock)
+	return a.set(key, value)
+}
 
-	if a.t2.Contains(key) {
-		a.t2.Set(key, value)
+func (a *Cache[K, V]) set(key K, value V) (evicted bool) {
+	a.stats.Set()
+	// case I: a hit in t1 or t2
+	if v, ok := a.items[key]; ok {
+		a.stats.Evict(cache.EvictReplaced)
+		a.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		a.promote(v)
 		return false
 	}
 
-	var evicted bool
-	if a.b1.Contains(key) {
-		var (
-			delta = 1
-			l1    = a.b1.Len()
-			l2    = a.b2.Len()
-		)
-		if l2 > l1 {
-			delta = l2 / l1
-		}
-		if a.p+delta >= a.capacity {
-			a.p = a.capacity
+	// case II and III: a hit in b1 or b2, adapt the target size of t1 then make room for the key in t2
+	if g, ok := a.ghosts[key]; ok {
+		if g.Value.frequent {
+			a.p = max(0, a.p-max(1, a.b1.Len()/a.b2.Len()))
 		} else {
-			a.p += a.capacity
+			a.p = min(a.capacity, a.p+max(1, a.b2.Len()/a.b1.Len()))
 		}
-
-		if a.t1.Len()+a.t2.Len() > a.capacity {
-			a.adaptEvict(false)
+		if len(a.items) >= a.capacity {
+			a.replace(g.Value.frequent)
 			evicted = true
 		}
-		a.b1.Remove(key)
-		a.t2.Set(key, value)
+		a.removeGhost(g)
+		a.items[key] = a.t2.PushBack(payload[K, V]{key: key, value: value, frequent: true})
 		return evicted
 	}
 
-	if a.b2.Contains(key) {
-		var (
-			delta = 1
-			l1    = a.b1.Len()
-			l2    = a.b2.Len()
-		)
-		if l1 > l2 {
-			delta = l1 / l2
-		}
-		if delta > a.p {
-			a.p = 0
+	// case IV: a miss in all lists
+	if a.t1.Len()+a.b1.Len() >= a.capacity {
+		if a.t1.Len() < a.capacity {
+			a.removeGhost(a.b1.Front())
+			if len(a.items) >= a.capacity {
+				a.replace(false)
+				evicted = true
+			}
 		} else {
-			a.p -= delta
+			a.evict(a.t1.Front(), cache.EvictCapacity)
+			evicted = true
 		}
-
-		if a.t1.Len()+a.t2.Len() > a.capacity {
-			a.adaptEvict(true)
+	} else if len(a.items)+len(a.ghosts) >= a.capacity {
+		if len(a.items)+len(a.ghosts) >= 2*a.capacity {
+			a.removeGhost(a.b2.Front())
+		}
+		if len(a.items) >= a.capacity {
+			a.replace(false)
 			evicted = true
 		}
-		a.b2.Remove(key)
-		a.t2.Set(key, value)
-		return evicted
-	}
-
-	if a.t1.Len()+a.t2.Len() > a.capacity {
-		a.adaptEvict(false)
-		evicted = true
 	}
-	if a.b1.Len() > a.capacity-a.p {
-		a.b1.PopOldest()
-		evicted = true
-	}
-	if a.b2.Len() > a.p {
-		a.b2.PopOldest()
-		evicted = true
-	}
-
-	a.t1.Set(key, value)
+	a.items[key] = a.t1.PushBack(payload[K, V]{key: key, value: value})
 	return evicted
 }
 
 // Get return the given key's value
-func (a *ARC) Get(key interface{}) (value interface{}, ok bool) {
+func (a *Cache[K, V]) Get(key K) (value V, ok bool) {
 	a.lock.Lock()
-	defer a.lock.Unlock()
+	defer a.evicted.Unlock(&a.lock)
+	return a.get(key)
+}
 
-	if value, ok := a.t1.Get(key); ok {
-		a.t1.Remove(key)
-		a.t1.Set(key, value)
+func (a *Cache[K, V]) get(key K) (value V, ok bool) {
+	v, ok := a.items[key]
+	if !ok {
+		a.stats.Miss()
 		return value, ok
 	}
+	a.promote(v)
+	a.stats.Hit()
+	return v.Value.value, ok
+}
 
-	if value, ok := a.t2.Get(key); ok {
-		return value, ok
+// All iterate over the items in the order of Keys without updating their recency or frequency, the items are
+// taken when the iteration starts so it's safe to modify the ARC in the loop
+func (a *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range a.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
 	}
-	return nil, false
 }
 
-// return the ARC length
-func (a *ARC) Len() int {
+// return the items in the order of Keys
+func (a *Cache[K, V]) snapshot() []payload[K, V] {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
-	return a.t1.Len() + a.t2.Len()
+	items := make([]payload[K, V], 0, len(a.items))
+	for _, l := range []*list.List[payload[K, V]]{a.t1, a.t2} {
+		for v := l.Front(); v != nil; v = v.Next() {
+			items = append(items, v.Value)
+		}
+	}
+	return items
 }
 
-// return all keys in cache
-func (a *ARC) Keys() []interface{} {
+// return the ARC length
+func (a *Cache[K, V]) Len() int {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
+	return len(a.items)
+}
 
-	k1 := a.t1.Keys()
-	k2 := a.t2.Keys()
-	return append(k1, k2...)
+// return all keys in cache, the ones in t1 then the ones in t2 both from the least recently used
+func (a *Cache[K, V]) Keys() []K {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	keys := make([]K, 0, len(a.items))
+	for _, l := range []*list.List[payload[K, V]]{a.t1, a.t2} {
+		for v := l.Front(); v != nil; v = v.Next() {
+			keys = append(keys, v.Value.key)
+		}
+	}
+	return keys
 }
 
 // Cotains check if the ARC contains the given key
-func (a *ARC) Contains(key interface{}) bool {
+func (a *Cache[K, V]) Contains(key K) bool {
+	_, ok := a.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (a *Cache[K, V]) peek(key K) (value V, ok bool) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
-	return a.t1.Contains(key) || a.t2.Contains(key)
+	v, ok := a.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
 }
 
-// Remove the item from cache by key
-func (a *ARC) Remove(key interface{}) bool {
+// Remove the item from cache by key, a key only remembered by the ghost lists is forgotten but reported as not existed
+func (a *Cache[K, V]) Remove(key K) bool {
 	a.lock.Lock()
-	defer a.lock.Unlock()
-	switch {
-	case a.t1.Remove(key):
+	defer a.evicted.Unlock(&a.lock)
+	if v, ok := a.items[key]; ok {
+		a.evict(v, cache.EvictRemoved)
 		return true
-	case a.t2.Remove(key):
-		return true
-	case a.b1.Remove(key):
-		return false
-	case a.b2.Remove(key):
-		return false
-	default:
-		return false
 	}
+	if g, ok := a.ghosts[key]; ok {
+		a.removeGhost(g)
+	}
+	return false
 }
 
-// Purge use to clear all items in ARC
-func (a *ARC) Purge() {
+// Purge use to clear all items in ARC, the statistics are kept, call ResetStats as well to clear them
+func (a *Cache[K, V]) Purge() {
 	a.lock.Lock()
-	defer a.lock.Unlock()
-	a.t1.Purge()
-	a.t2.Purge()
-	a.b1.Purge()
-	a.b2.Purge()
+	defer a.evicted.Unlock(&a.lock)
+	a.purge()
 }
 
-// Remove and return the oldest item from ARC
-func (a *ARC) PopOldest() (key, value interface{}) {
-	a.lock.Lock()
-	defer a.lock.Unlock()
+// clear all items and report them as purged, the lock must be held
+func (a *Cache[K, V]) purge() {
+	for _, l := range []*list.List[payload[K, V]]{a.t1, a.t2} {
+		for v := l.Front(); v != nil; v = v.Next() {
+			a.stats.Evict(cache.EvictPurged)
+			a.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+		}
+		l.Init()
+	}
+	a.b1.Init()
+	a.b2.Init()
+	a.items = make(map[K]*list.Element[payload[K, V]])
+	a.ghosts = make(map[K]*list.Element[ghost[K]])
+	a.p = 0
+}
 
-	if a.t1.Len() > 0 {
-		return a.t1.PopOldest()
+// Remove and return the oldest item from ARC, the least recently used one in t1 if there is any otherwise in t2
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (a *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = a.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the ARC is empty
+func (a *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	a.lock.Lock()
+	defer a.evicted.Unlock(&a.lock)
+	v := a.t1.Front()
+	if v == nil {
+		v = a.t2.Front()
 	}
-	if a.t2.Len() > 0 {
-		return a.t2.PopOldest()
+	if v == nil {
+		return key, value, false
 	}
-	return nil, nil
+	a.removeItem(v)
+	return v.Value.key, v.Value.value, true
 }
 
 // return the value if the key exist, otherwise update the key by given value similar with redis SETNX
-func (a *ARC) GetOrSet(key, value interface{}) (newValue interface{}, isGet bool) {
-	if v, ok := a.Get(key); ok {
+func (a *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	a.lock.Lock()
+	defer a.evicted.Unlock(&a.lock)
+	if v, ok := a.get(key); ok {
 		return v, ok
 	}
-	a.Set(key, value)
+	a.set(key, value)
 	return value, false
 }
 
-// adaptEvict used to evicted value
-func (a *ARC) adaptEvict(inB2 bool) {
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into ARC
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (a *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return a.loads.Load(ctx, key, a.Get, a.peek, a.Set, loader, &a.stats)
+}
+
+// promote move a hit item to the most recently used end of t2
+func (a *Cache[K, V]) promote(e *list.Element[payload[K, V]]) {
+	e.Value.frequent = true
+	a.t2.PushBackElement(e)
+}
+
+// replace evict the least recently used item of t1 into b1 if t1 exceeds its target size, otherwise the least
+// recently used item of t2 into b2, inB2 tell if the key being requested was found in b2
+func (a *Cache[K, V]) replace(inB2 bool) {
 	l1 := a.t1.Len()
-	if l1 > 0 && (l1 > a.p || (inB2 && l1 == a.p)) {
-		if a.t1.Len() >= 1 {
-			a.b1.Set(a.t1.PopOldest())
-		} else {
-			a.t1.PopOldest()
-		}
+	victim := a.t2.Front()
+	if l1 >= 1 && (l1 > a.p || (inB2 && l1 == a.p) || victim == nil) {
+		victim = a.t1.Front()
+	}
+	a.evict(victim, cache.EvictCapacity)
+	g := ghost[K]{key: victim.Value.key, frequent: victim.Value.frequent}
+	if g.frequent {
+		a.ghosts[g.key] = a.b2.PushBack(g)
 	} else {
-		if a.t2.Len() >= 1 {
-			a.b2.Set(a.t2.PopOldest())
-		} else {
-			a.t2.PopOldest()
-		}
+		a.ghosts[g.key] = a.b1.PushBack(g)
+	}
+}
+
+// remove item from arc and notify the eviction callback
+func (a *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	a.removeItem(e)
+	a.stats.Evict(reason)
+	a.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from t1 or t2
+func (a *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	if e.Value.frequent {
+		a.t2.Remove(e)
+	} else {
+		a.t1.Remove(e)
+	}
+	delete(a.items, e.Value.key)
+}
+
+// remove key from b1 or b2
+func (a *Cache[K, V]) removeGhost(e *list.Element[ghost[K]]) {
+	if e.Value.frequent {
+		a.b2.Remove(e)
+	} else {
+		a.b1.Remove(e)
 	}
+	delete(a.ghosts, e.Value.key)
 }
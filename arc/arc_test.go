@@ -1,6 +1,11 @@
 package arc
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+)
 
 func TestARC_Set(t *testing.T) {
 	a := NewARCCache(32)
@@ -109,6 +114,32 @@ func TestARC_Remove(t *testing.T) {
 	}
 }
 
+func TestARC_Typed(t *testing.T) {
+	a := New[string, int](32)
+	a.Set("a", 1)
+	if v, ok := a.Get("a"); !ok || v != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", v, ok)
+	}
+	if v, ok := a.Get("b"); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if k, v := a.PopOldest(); k != "a" || v != 1 {
+		t.Errorf("expect a,1;got %v,%v", k, v)
+	}
+}
+
+func TestARC_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestARC_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
 func BenchmarkARC_Set(b *testing.B) {
 	b.StopTimer()
 	a := NewARCCache(8096)
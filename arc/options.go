@@ -0,0 +1,22 @@
+package arc
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the ARC created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the ARC except the ones returned by PopOldest,
+// an item evicted to a ghost list is reported with cache.EvictCapacity since its value is dropped
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(a *Cache[K, V]) {
+		a.evicted.SetCallback(fn)
+	}
+}
+
+// WithCodec set the codecs the keys and values are encoded by in Snapshot and Restore, cache.GobCodec by default
+func WithCodec[K comparable, V any](keys cache.Codec[K], values cache.Codec[V]) Option[K, V] {
+	return func(a *Cache[K, V]) {
+		a.keys = keys
+		a.values = values
+	}
+}
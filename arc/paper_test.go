@@ -0,0 +1,211 @@
+package arc
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/FelixSeptem/collections/lru"
+)
+
+// request replay a request of the paper: a hit if the key is cached, otherwise the key is fetched into cache
+func request[K comparable](a *Cache[K, K], key K) (hit bool) {
+	if _, ok := a.Get(key); ok {
+		return true
+	}
+	a.Set(key, key)
+	return false
+}
+
+// lists return t1, t2, b1 and b2 from the least recently used
+func (a *Cache[K, V]) lists() string {
+	var t1, t2, b1, b2 []K
+	for v := a.t1.Front(); v != nil; v = v.Next() {
+		t1 = append(t1, v.Value.key)
+	}
+	for v := a.t2.Front(); v != nil; v = v.Next() {
+		t2 = append(t2, v.Value.key)
+	}
+	for v := a.b1.Front(); v != nil; v = v.Next() {
+		b1 = append(b1, v.Value.key)
+	}
+	for v := a.b2.Front(); v != nil; v = v.Next() {
+		b2 = append(b2, v.Value.key)
+	}
+	return fmt.Sprint(t1, t2, b1, b2)
+}
+
+func TestARC_PaperTrace(t *testing.T) {
+	// worked by hand following the algorithm of the paper with c = 2
+	var (
+		a     = New[int, int](2)
+		trace = []int{1, 2, 3, 1, 1, 2, 3, 1, 4, 2, 3, 5}
+		hits  = []bool{false, false, false, false, true, false, false, false, false, false, false, false}
+		p     = []int{0, 0, 0, 0, 0, 0, 1, 0, 0, 1, 0, 0}
+	)
+	for i, key := range trace {
+		if hit := request(a, key); hit != hits[i] {
+			t.Errorf("request %d of %d: expect hit %v,got %v", i, key, hits[i], hit)
+		}
+		if a.p != p[i] {
+			t.Errorf("request %d of %d: expect p %d,got %d", i, key, p[i], a.p)
+		}
+	}
+	if lists := a.lists(); lists != "[5] [3] [4] [2]" {
+		t.Errorf("expect [5] [3] [4] [2],got %s", lists)
+	}
+}
+
+// reference is a literal transcription of the ARC pseudocode in the paper, lists are kept from LRU to MRU
+type reference struct {
+	c, p           int
+	t1, t2, b1, b2 []int
+}
+
+func indexOf(l []int, x int) int {
+	for i, v := range l {
+		if v == x {
+			return i
+		}
+	}
+	return -1
+}
+
+func without(l []int, i int) []int {
+	return append(l[:i:i], l[i+1:]...)
+}
+
+func (r *reference) replace(x int) {
+	if len(r.t1) >= 1 && ((indexOf(r.b2, x) >= 0 && len(r.t1) == r.p) || len(r.t1) > r.p) {
+		r.b1 = append(r.b1, r.t1[0])
+		r.t1 = r.t1[1:]
+	} else {
+		r.b2 = append(r.b2, r.t2[0])
+		r.t2 = r.t2[1:]
+	}
+}
+
+func (r *reference) request(x int) (hit bool) {
+	if i := indexOf(r.t1, x); i >= 0 {
+		r.t1 = without(r.t1, i)
+		r.t2 = append(r.t2, x)
+		return true
+	}
+	if i := indexOf(r.t2, x); i >= 0 {
+		r.t2 = append(without(r.t2, i), x)
+		return true
+	}
+	if i := indexOf(r.b1, x); i >= 0 {
+		delta := 1
+		if len(r.b1) < len(r.b2) {
+			delta = len(r.b2) / len(r.b1)
+		}
+		r.p = min(r.c, r.p+delta)
+		r.replace(x)
+		r.b1 = without(r.b1, indexOf(r.b1, x))
+		r.t2 = append(r.t2, x)
+		return false
+	}
+	if i := indexOf(r.b2, x); i >= 0 {
+		delta := 1
+		if len(r.b2) < len(r.b1) {
+			delta = len(r.b1) / len(r.b2)
+		}
+		r.p = max(0, r.p-delta)
+		r.replace(x)
+		r.b2 = without(r.b2, indexOf(r.b2, x))
+		r.t2 = append(r.t2, x)
+		return false
+	}
+	l1 := len(r.t1) + len(r.b1)
+	l2 := len(r.t2) + len(r.b2)
+	if l1 == r.c {
+		if len(r.t1) < r.c {
+			r.b1 = r.b1[1:]
+			r.replace(x)
+		} else {
+			r.t1 = r.t1[1:]
+		}
+	} else if l1 < r.c && l1+l2 >= r.c {
+		if l1+l2 == 2*r.c {
+			r.b2 = r.b2[1:]
+		}
+		r.replace(x)
+	}
+	r.t1 = append(r.t1, x)
+	return false
+}
+
+func (r *reference) lists() string {
+	nilIfEmpty := func(l []int) []int {
+		if len(l) == 0 {
+			return nil
+		}
+		return l
+	}
+	return fmt.Sprint(nilIfEmpty(r.t1), nilIfEmpty(r.t2), nilIfEmpty(r.b1), nilIfEmpty(r.b2))
+}
+
+// traces return synthetic workloads mixing the patterns the paper discusses: a skewed working set, loops larger
+// than the cache and one-off scans
+func traces(seed int64, length int) map[string][]int {
+	rnd := rand.New(rand.NewSource(seed))
+	zipf := rand.NewZipf(rnd, 1.2, 1, 255)
+	var skewed, loop, mixed []int
+	for i := 0; i < length; i++ {
+		skewed = append(skewed, int(zipf.Uint64()))
+		loop = append(loop, i%40)
+		switch {
+		case i%200 < 50:
+			// a scan touching every key once
+			mixed = append(mixed, 1000+i)
+		default:
+			mixed = append(mixed, int(zipf.Uint64()))
+		}
+	}
+	return map[string][]int{"skewed": skewed, "loop": loop, "mixed": mixed}
+}
+
+func TestARC_ReplayReference(t *testing.T) {
+	for _, size := range []int{1, 2, 7, 32} {
+		for name, trace := range traces(int64(size), 5000) {
+			a := New[int, int](size)
+			r := &reference{c: size}
+			for i, key := range trace {
+				if got, want := request(a, key), r.request(key); got != want {
+					t.Fatalf("%s/%d request %d of %d: expect hit %v,got %v", name, size, i, key, want, got)
+				}
+				if a.p != r.p {
+					t.Fatalf("%s/%d request %d of %d: expect p %d,got %d", name, size, i, key, r.p, a.p)
+				}
+				if got, want := a.lists(), r.lists(); got != want {
+					t.Fatalf("%s/%d request %d of %d: expect %s,got %s", name, size, i, key, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestARC_HitRatio(t *testing.T) {
+	// ARC shall not be worse than LRU on any of the workloads and beat it once scans are involved
+	const size = 32
+	for name, trace := range traces(1, 20000) {
+		a := New[int, int](size)
+		l := lru.New[int, int](size)
+		var arcHits, lruHits int
+		for _, key := range trace {
+			if request(a, key) {
+				arcHits++
+			}
+			if _, ok := l.Get(key); ok {
+				lruHits++
+			} else {
+				l.Set(key, key)
+			}
+		}
+		t.Logf("%s: arc %.3f lru %.3f", name, float64(arcHits)/float64(len(trace)), float64(lruHits)/float64(len(trace)))
+		if arcHits < lruHits || (name == "mixed" && arcHits == lruHits) {
+			t.Errorf("%s: expect arc hits more than %d,got %d", name, lruHits, arcHits)
+		}
+	}
+}
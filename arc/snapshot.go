@@ -0,0 +1,151 @@
+package arc
+
+import (
+	"io"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/snapshot"
+)
+
+// the policy name written in the snapshot header
+const policy = "arc"
+
+// state is what ARC keeps in the snapshot
+type state[K comparable, V any] struct {
+	p      int
+	t1, t2 []payload[K, V]
+	b1, b2 []K
+}
+
+// Snapshot write the target size of t1, the items of t1 and t2 and the keys of b1 and b2, all from the least recently
+// used, into w, the keys and values are encoded by the codecs set by WithCodec
+func (a *Cache[K, V]) Snapshot(w io.Writer) error {
+	s := a.state()
+	sw := snapshot.NewWriter(w, policy)
+	sw.Uvarint(uint64(s.p))
+	for _, items := range [][]payload[K, V]{s.t1, s.t2} {
+		sw.Uvarint(uint64(len(items)))
+		for _, v := range items {
+			snapshot.Encode(sw, a.keys, v.key)
+			snapshot.Encode(sw, a.values, v.value)
+		}
+	}
+	for _, keys := range [][]K{s.b1, s.b2} {
+		sw.Uvarint(uint64(len(keys)))
+		for _, k := range keys {
+			snapshot.Encode(sw, a.keys, k)
+		}
+	}
+	return sw.Flush()
+}
+
+// Restore replace the items and ghosts of ARC by the ones written by Snapshot, the current items are reported as
+// purged, if the snapshot is taken from a larger ARC the least recently used items are evicted by capacity as Set
+// does and the ghosts are dropped to fit
+// ARC is left unchanged if r doesn't hold a valid snapshot
+func (a *Cache[K, V]) Restore(r io.Reader) error {
+	sr, err := snapshot.NewReader(r, policy)
+	if err != nil {
+		return err
+	}
+	s := state[K, V]{p: int(min(sr.Uvarint(), uint64(a.capacity)))}
+	s.t1 = a.decodeItems(sr, false)
+	s.t2 = a.decodeItems(sr, true)
+	s.b1 = a.decodeKeys(sr)
+	s.b2 = a.decodeKeys(sr)
+	if err := sr.Err(); err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.evicted.Unlock(&a.lock)
+	a.purge()
+	a.p = s.p
+	for _, v := range append(s.t1, s.t2...) {
+		// a key written twice keeps the later one
+		if e, ok := a.items[v.key]; ok {
+			a.removeItem(e)
+		}
+		if v.frequent {
+			a.items[v.key] = a.t2.PushBack(v)
+		} else {
+			a.items[v.key] = a.t1.PushBack(v)
+		}
+	}
+	for i, keys := range [][]K{s.b1, s.b2} {
+		frequent := i == 1
+		for _, k := range keys {
+			if _, ok := a.items[k]; ok {
+				continue
+			}
+			if g, ok := a.ghosts[k]; ok {
+				a.removeGhost(g)
+			}
+			if frequent {
+				a.ghosts[k] = a.b2.PushBack(ghost[K]{key: k, frequent: true})
+			} else {
+				a.ghosts[k] = a.b1.PushBack(ghost[K]{key: k})
+			}
+		}
+	}
+
+	// keep the invariants of ARC: |t1|+|t2| <= c, |t1|+|b1| <= c and |t1|+|t2|+|b1|+|b2| <= 2c
+	for len(a.items) > a.capacity {
+		if v := a.t1.Front(); v != nil {
+			a.evict(v, cache.EvictCapacity)
+		} else {
+			a.evict(a.t2.Front(), cache.EvictCapacity)
+		}
+	}
+	for a.t1.Len()+a.b1.Len() > a.capacity {
+		a.removeGhost(a.b1.Front())
+	}
+	for len(a.items)+len(a.ghosts) > 2*a.capacity {
+		a.removeGhost(a.b2.Front())
+	}
+	return nil
+}
+
+// return the target size of t1, the items and the ghosts of ARC
+func (a *Cache[K, V]) state() state[K, V] {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	s := state[K, V]{p: a.p}
+	for v := a.t1.Front(); v != nil; v = v.Next() {
+		s.t1 = append(s.t1, v.Value)
+	}
+	for v := a.t2.Front(); v != nil; v = v.Next() {
+		s.t2 = append(s.t2, v.Value)
+	}
+	for v := a.b1.Front(); v != nil; v = v.Next() {
+		s.b1 = append(s.b1, v.Value.key)
+	}
+	for v := a.b2.Front(); v != nil; v = v.Next() {
+		s.b2 = append(s.b2, v.Value.key)
+	}
+	return s
+}
+
+// read the items of t1 or t2
+func (a *Cache[K, V]) decodeItems(sr *snapshot.Reader, frequent bool) []payload[K, V] {
+	n := sr.Uvarint()
+	items := make([]payload[K, V], 0, min(n, uint64(a.capacity)))
+	for i := uint64(0); i < n && sr.Err() == nil; i++ {
+		items = append(items, payload[K, V]{
+			key:      snapshot.Decode(sr, a.keys),
+			value:    snapshot.Decode(sr, a.values),
+			frequent: frequent,
+		})
+	}
+	return items
+}
+
+// read the keys of b1 or b2
+func (a *Cache[K, V]) decodeKeys(sr *snapshot.Reader) []K {
+	n := sr.Uvarint()
+	keys := make([]K, 0, min(n, uint64(a.capacity)))
+	for i := uint64(0); i < n && sr.Err() == nil; i++ {
+		keys = append(keys, snapshot.Decode(sr, a.keys))
+	}
+	return keys
+}
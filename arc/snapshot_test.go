@@ -0,0 +1,108 @@
+package arc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestARC_Snapshot(t *testing.T) {
+	a := New[int, int](4)
+	for _, k := range []int{1, 2, 3, 4, 1, 2, 5, 6, 3, 7} {
+		if _, ok := a.Get(k); !ok {
+			a.Set(k, k*10)
+		}
+	}
+	var buf bytes.Buffer
+	if err := a.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	r := New[int, int](4)
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	a.ResetStats()
+	expectSame(t, a, r)
+	// both ARCs adapt the same way afterwards
+	for _, k := range []int{4, 8, 1, 9, 5} {
+		a.Set(k, k)
+		r.Set(k, k)
+	}
+	expectSame(t, a, r)
+}
+
+// expectSame check if r holds the same items and ghost keys with the same target size of t1 as a
+func expectSame(t *testing.T, a, r *Cache[int, int]) {
+	t.Helper()
+	if diff := cmp.Diff(a.Keys(), r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	for _, k := range a.Keys() {
+		want, _ := a.Get(k)
+		if v, ok := r.Get(k); !ok || v != want {
+			t.Errorf("expect %d with true for %d,got %d with %v", want, k, v, ok)
+		}
+	}
+	wantHits, wantMisses, wantMax, wantSize := a.Info()
+	if hits, misses, maxSize, size := r.Info(); hits != wantHits || misses != wantMisses || maxSize != wantMax || size != wantSize {
+		t.Errorf("expect %d,%d,%d,%d;got %d %d %d %d", wantHits, wantMisses, wantMax, wantSize, hits, misses, maxSize, size)
+	}
+	want, got := a.state(), r.state()
+	if got.p != want.p {
+		t.Errorf("expect p %d,got %d", want.p, got.p)
+	}
+	if diff := cmp.Diff([][]int{want.b1, want.b2}, [][]int{got.b1, got.b2}); diff != "" {
+		t.Errorf("ghost keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestARC_RestoreSmaller(t *testing.T) {
+	a := New[int, int](8)
+	for i := range 16 {
+		a.Set(i, i)
+		if i%2 == 0 {
+			a.Get(i)
+		}
+	}
+	var buf bytes.Buffer
+	if err := a.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	var evicted int
+	r := New[int, int](2, WithOnEvict(func(key, value int, reason cache.EvictReason) {
+		if reason == cache.EvictCapacity {
+			evicted++
+		}
+	}))
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	// the items beyond the capacity are evicted as Set does
+	if s := r.Stats(); evicted != 6 || s.Evictions[cache.EvictCapacity] != 6 {
+		t.Errorf("expect 6 capacity evictions,got %d with %v", evicted, s.Evictions)
+	}
+	if r.Len() != 2 || r.p > 2 || r.t1.Len()+r.b1.Len() > 2 || len(r.items)+len(r.ghosts) > 4 {
+		t.Errorf("expect restored ARC within capacity,got %+v", r.state())
+	}
+}
+
+func TestARC_RestoreInvalid(t *testing.T) {
+	a := New[int, int](4)
+	a.Set(1, 1)
+	a.Set(2, 2)
+	var buf bytes.Buffer
+	if err := a.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	data := buf.Bytes()
+	data[4] = 2 // the version
+	if err := a.Restore(bytes.NewReader(data)); !errors.Is(err, cache.ErrInvalidSnapshot) {
+		t.Errorf("expect ErrInvalidSnapshot,got %v", err)
+	}
+	if diff := cmp.Diff([]int{1, 2}, a.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,165 @@
+// Package bidict implement a thread safe one-to-one map can be looked up by key as well as by value,
+// inspired by python bidict https://bidict.readthedocs.io
+package bidict
+
+import (
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrDuplicateValue is returned by Put when the value belongs to another key and the policy is RejectDuplicate
+var ErrDuplicateValue = errors.New("bidict: value already belongs to another key")
+
+// DuplicatePolicy choose what Put does with a value already belongs to another key
+type DuplicatePolicy int
+
+const (
+	// RejectDuplicate leave the dict unchanged and return ErrDuplicateValue
+	RejectDuplicate DuplicatePolicy = iota
+	// OverwriteDuplicate remove the other key so the value belongs to the key put
+	OverwriteDuplicate
+)
+
+// shared is the state a BiDict shares with its inverse
+type shared struct {
+	lock   sync.RWMutex
+	policy DuplicatePolicy
+}
+
+// BiDict is a one-to-one map from keys to values, the zero value isn't ready to use
+type BiDict[K comparable, V comparable] struct {
+	*shared
+	forward  map[K]V
+	backward map[V]K
+	inverse  *BiDict[V, K]
+}
+
+// New return an empty BiDict handles duplicate values by policy
+func New[K comparable, V comparable](policy DuplicatePolicy) *BiDict[K, V] {
+	s := &shared{policy: policy}
+	d := &BiDict[K, V]{
+		shared:   s,
+		forward:  make(map[K]V),
+		backward: make(map[V]K),
+	}
+	d.inverse = &BiDict[V, K]{
+		shared:   s,
+		forward:  d.backward,
+		backward: d.forward,
+		inverse:  d,
+	}
+	return d
+}
+
+// Inverse return the view maps values to keys, it shares the storage and lock with d so changes made through
+// either are seen by both
+func (d *BiDict[K, V]) Inverse() *BiDict[V, K] {
+	return d.inverse
+}
+
+// Put bind key to value, the value key was bound to is released, if value belongs to another key
+// it's handled by the duplicate policy
+func (d *BiDict[K, V]) Put(key K, value V) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if other, ok := d.backward[value]; ok {
+		if other == key {
+			return nil
+		}
+		if d.policy == RejectDuplicate {
+			return ErrDuplicateValue
+		}
+		delete(d.forward, other)
+	}
+	if old, ok := d.forward[key]; ok {
+		delete(d.backward, old)
+	}
+	d.forward[key] = value
+	d.backward[value] = key
+	return nil
+}
+
+// GetByKey return the value of key and whether it exists
+func (d *BiDict[K, V]) GetByKey(key K) (value V, ok bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	value, ok = d.forward[key]
+	return value, ok
+}
+
+// GetByValue return the key of value and whether it exists
+func (d *BiDict[K, V]) GetByValue(value V) (key K, ok bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	key, ok = d.backward[value]
+	return key, ok
+}
+
+// DeleteByKey remove key with its value, return if it has existed before
+func (d *BiDict[K, V]) DeleteByKey(key K) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	value, ok := d.forward[key]
+	if ok {
+		delete(d.forward, key)
+		delete(d.backward, value)
+	}
+	return ok
+}
+
+// DeleteByValue remove value with its key, return if it has existed before
+func (d *BiDict[K, V]) DeleteByValue(value V) bool {
+	return d.inverse.DeleteByKey(value)
+}
+
+// return the number of pairs
+func (d *BiDict[K, V]) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.forward)
+}
+
+// Keys return all keys in no particular order
+func (d *BiDict[K, V]) Keys() []K {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	keys := make([]K, 0, len(d.forward))
+	for k := range d.forward {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values return all values in no particular order
+func (d *BiDict[K, V]) Values() []V {
+	return d.inverse.Keys()
+}
+
+// All iterate over the pairs in no particular order, they're taken when the iteration starts
+// so it's safe to modify the dict in the loop
+func (d *BiDict[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.lock.RLock()
+		keys := make([]K, 0, len(d.forward))
+		values := make([]V, 0, len(d.forward))
+		for k, v := range d.forward {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		d.lock.RUnlock()
+		for i, k := range keys {
+			if !yield(k, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Purge use to clear all pairs
+func (d *BiDict[K, V]) Purge() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	clear(d.forward)
+	clear(d.backward)
+}
@@ -0,0 +1,102 @@
+package bidict
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBiDict_Put(t *testing.T) {
+	d := New[int, string](RejectDuplicate)
+	if err := d.Put(1, "one"); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	if err := d.Put(1, "one"); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	if err := d.Put(2, "one"); err != ErrDuplicateValue {
+		t.Errorf("expect %v,got %v", ErrDuplicateValue, err)
+	}
+	if v, ok := d.GetByKey(2); ok {
+		t.Errorf("expect false,got %v with %v", v, ok)
+	}
+	// rebinding a key releases its old value
+	d.Put(1, "uno")
+	if k, ok := d.GetByValue("one"); ok {
+		t.Errorf("expect false,got %v with %v", k, ok)
+	}
+	if k, ok := d.GetByValue("uno"); !ok || k != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", k, ok)
+	}
+	if l := d.Len(); l != 1 {
+		t.Errorf("expect 1,got %d", l)
+	}
+}
+
+func TestBiDict_Overwrite(t *testing.T) {
+	d := New[int, string](OverwriteDuplicate)
+	d.Put(1, "one")
+	d.Put(2, "two")
+	if err := d.Put(2, "one"); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	if v, ok := d.GetByKey(1); ok {
+		t.Errorf("expect false,got %v with %v", v, ok)
+	}
+	if k, ok := d.GetByValue("two"); ok {
+		t.Errorf("expect false,got %v with %v", k, ok)
+	}
+	if k, ok := d.GetByValue("one"); !ok || k != 2 || d.Len() != 1 {
+		t.Errorf("expect 2 with true,got %v with %v", k, ok)
+	}
+}
+
+func TestBiDict_Inverse(t *testing.T) {
+	d := New[int, string](RejectDuplicate)
+	inv := d.Inverse()
+	if inv.Inverse() != d {
+		t.Errorf("expect the inverse of inverse is itself")
+	}
+	d.Put(1, "one")
+	inv.Put("two", 2)
+	if err := inv.Put("deux", 2); err != ErrDuplicateValue {
+		t.Errorf("expect %v,got %v", ErrDuplicateValue, err)
+	}
+	if v, ok := d.GetByKey(2); !ok || v != "two" {
+		t.Errorf("expect two with true,got %v with %v", v, ok)
+	}
+	if k, ok := inv.GetByKey("one"); !ok || k != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", k, ok)
+	}
+	keys, values := d.Keys(), inv.Keys()
+	sort.Ints(keys)
+	sort.Strings(values)
+	if !cmp.Equal(keys, []int{1, 2}) || !cmp.Equal(values, []string{"one", "two"}) {
+		t.Errorf("expect [1 2] [one two],got %v %v", keys, values)
+	}
+	if ok := inv.DeleteByValue(1); !ok || d.Len() != 1 || inv.Len() != 1 {
+		t.Errorf("expect true and 1 left,got %v with %d", ok, d.Len())
+	}
+	if ok := d.DeleteByValue("one"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	d.Purge()
+	if l := inv.Len(); l != 0 || len(d.Values()) != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestBiDict_All(t *testing.T) {
+	d := New[int, string](RejectDuplicate)
+	d.Put(1, "one")
+	d.Put(2, "two")
+	seen := make(map[string]int)
+	for v, k := range d.Inverse().All() {
+		seen[v] = k
+		d.DeleteByKey(k)
+	}
+	if !cmp.Equal(seen, map[string]int{"one": 1, "two": 2}) || d.Len() != 0 {
+		t.Errorf("expect map[one:1 two:2],got %v", seen)
+	}
+}
@@ -0,0 +1,56 @@
+// Package cache declares the method set shared by the cache policies in this module, so callers can swap policies by configuration
+package cache
+
+import (
+	"context"
+	"iter"
+)
+
+// Cache is implemented by lru.Cache, lfu.Cache, arc.Cache, twoq.Cache, wtinylfu.Cache, sieve.Cache, s3fifo.Cache,
+// clock.Cache and clockpro.Cache
+type Cache[K comparable, V any] interface {
+	// Set add a new item into cache, return if another item has been evicted to make room for it
+	Set(key K, value V) (evicted bool)
+	// Get return the value of the given key and whether it exists
+	Get(key K) (value V, ok bool)
+	// Contains check if the cache contains the given key without updating its recency or frequency
+	Contains(key K) bool
+	// Remove the given key item return if the key has existed before
+	Remove(key K) bool
+	// PopOldest remove and return the first item of Keys, the one the cache would evict next, zero values if cache is empty
+	PopOldest() (key K, value V)
+	// GetOrSet return the value if the key exist, otherwise set the key by given value, it's done atomically
+	GetOrSet(key K, value V) (newValue V, isGet bool)
+	// GetOrLoad return the value if the key exist, otherwise load it by loader and set it into cache
+	// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+	// a failed load is not cached
+	GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error)
+	// Keys return all keys the cache hold in eviction order, the next evicted first, unless the policy documents
+	// another order as the one whose eviction depends on the items to come does
+	Keys() []K
+	// All iterate over the items in the order of Keys without updating their recency or frequency, the items are
+	// taken when the iteration starts so it's safe to modify the cache in the loop
+	All() iter.Seq2[K, V]
+	// Len return the number of items in cache
+	Len() int
+	// Purge clear all items in cache
+	Purge()
+	// Cap return the max capacity of cache
+	Cap() int
+	// Info return the cache running information
+	Info() (hits int, misses int, maxSize int, currentSize int)
+	// Stats return a snapshot of the cache statistics, they're kept until ResetStats is called
+	Stats() Stats
+	// ResetStats set all the statistics to zero
+	ResetStats()
+}
+
+// Popper is implemented by the caches which can tell an empty cache from popping an item of zero key and value,
+// all the policies in this module do
+type Popper[K comparable, V any] interface {
+	// TryPopOldest remove and return the item PopOldest would, ok is false if the cache is empty
+	TryPopOldest() (key K, value V, ok bool)
+}
+
+// Loader load the value of key on a cache miss
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
@@ -0,0 +1,23 @@
+package cachetest
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// ParallelGet benchmark Get of the existing keys from GOMAXPROCS goroutines against a full cache created by
+// newCache, the policies whose hits take only the read lock or no lock at all are compared with the others by it
+func ParallelGet(b *testing.B, newCache Factory) {
+	const size = 1 << 16
+	c := newCache(size)
+	for i := 0; i < size; i++ {
+		c.Set(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			c.Get(r.Intn(size))
+		}
+	})
+}
@@ -0,0 +1,491 @@
+// Package cachetest implement a conformance test suite every cache.Cache implementation shall pass
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// Factory return an empty cache with the given capacity
+type Factory func(size int) cache.Cache[int, int]
+
+// Run runs the conformance test suite against caches created by newCache
+func Run(t *testing.T, newCache Factory) {
+	t.Helper()
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, newCache Factory)
+	}{
+		{"SetGet", testSetGet},
+		{"Update", testUpdate},
+		{"Contains", testContains},
+		{"Remove", testRemove},
+		{"Capacity", testCapacity},
+		{"PopOldest", testPopOldest},
+		{"TryPopOldest", testTryPopOldest},
+		{"GetOrSet", testGetOrSet},
+		{"GetOrLoad", testGetOrLoad},
+		{"Keys", testKeys},
+		{"All", testAll},
+		{"Purge", testPurge},
+		{"Info", testInfo},
+		{"Stats", testStats},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newCache)
+		})
+	}
+}
+
+func testSetGet(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if v, ok := c.Get(1); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if evicted := c.Set(1, 10); evicted {
+		t.Errorf("expect false,got %v", evicted)
+	}
+	if v, ok := c.Get(1); !ok || v != 10 {
+		t.Errorf("expect 10 with true,got %v with %v", v, ok)
+	}
+}
+
+func testUpdate(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	c.Set(1, 10)
+	if evicted := c.Set(1, 11); evicted {
+		t.Errorf("expect false,got %v", evicted)
+	}
+	if v, ok := c.Get(1); !ok || v != 11 {
+		t.Errorf("expect 11 with true,got %v with %v", v, ok)
+	}
+	if l := c.Len(); l != 1 {
+		t.Errorf("expect 1,got %d", l)
+	}
+}
+
+func testContains(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if ok := c.Contains(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	c.Set(1, 10)
+	if ok := c.Contains(1); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+}
+
+func testRemove(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if ok := c.Remove(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	c.Set(1, 10)
+	c.Set(2, 20)
+	if ok := c.Remove(1); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if _, ok := c.Get(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if l := c.Len(); l != 1 {
+		t.Errorf("expect 1,got %d", l)
+	}
+}
+
+func testCapacity(t *testing.T, newCache Factory) {
+	const size = 8
+	c := newCache(size)
+	if v := c.Cap(); v != size {
+		t.Fatalf("expect %d,got %d", size, v)
+	}
+	var evictions int
+	for i := 0; i < size*4; i++ {
+		if c.Set(i, i) {
+			evictions++
+		}
+		// touch a few keys so frequency and recency based policies have something to work with
+		c.Get(i / 2)
+		if l := c.Len(); l > size {
+			t.Fatalf("expect at most %d items,got %d", size, l)
+		}
+	}
+	if l := c.Len(); l != size {
+		t.Errorf("expect %d,got %d", size, l)
+	}
+	if evictions != size*3 {
+		t.Errorf("expect %d evictions,got %d", size*3, evictions)
+	}
+	for _, k := range c.Keys() {
+		if v, ok := c.Get(k); !ok || v != k {
+			t.Errorf("expect %d with true,got %v with %v", k, v, ok)
+		}
+	}
+}
+
+func testPopOldest(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if k, v := c.PopOldest(); k != 0 || v != 0 {
+		t.Errorf("expect 0,0;got %v,%v", k, v)
+	}
+	for i := 1; i <= 4; i++ {
+		c.Set(i, i*10)
+	}
+	next := c.Keys()[0]
+	k, v := c.PopOldest()
+	if k != next || v != k*10 {
+		t.Errorf("expect %d,%d;got %v,%v", next, next*10, k, v)
+	}
+	if ok := c.Contains(k); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if l := c.Len(); l != 3 {
+		t.Errorf("expect 3,got %d", l)
+	}
+}
+
+func testTryPopOldest(t *testing.T, newCache Factory) {
+	c, ok := newCache(8).(cache.Popper[int, int])
+	if !ok {
+		t.Skip("cache isn't a cache.Popper")
+	}
+	if k, v, ok := c.TryPopOldest(); k != 0 || v != 0 || ok {
+		t.Errorf("expect 0,0,false;got %v,%v,%v", k, v, ok)
+	}
+	// an item of zero key and value is told from an empty cache
+	c.(cache.Cache[int, int]).Set(0, 0)
+	if k, v, ok := c.TryPopOldest(); k != 0 || v != 0 || !ok {
+		t.Errorf("expect 0,0,true;got %v,%v,%v", k, v, ok)
+	}
+	if _, _, ok := c.TryPopOldest(); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+}
+
+func testGetOrSet(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if v, ok := c.GetOrSet(1, 10); ok || v != 10 {
+		t.Errorf("expect 10 with false,got %v with %v", v, ok)
+	}
+	if v, ok := c.GetOrSet(1, 11); !ok || v != 10 {
+		t.Errorf("expect 10 with true,got %v with %v", v, ok)
+	}
+	if hits, misses, _, _ := c.Info(); hits != 1 || misses != 1 {
+		t.Errorf("expect 1,1;got %d %d", hits, misses)
+	}
+}
+
+func testGetOrLoad(t *testing.T, newCache Factory) {
+	ctx := context.Background()
+	c := newCache(8)
+	loads := 0
+	loader := func(ctx context.Context, key int) (int, error) {
+		loads++
+		return key * 10, nil
+	}
+	if v, err := c.GetOrLoad(ctx, 1, loader); err != nil || v != 10 {
+		t.Errorf("expect 10 with nil,got %v with %v", v, err)
+	}
+	if v, err := c.GetOrLoad(ctx, 1, loader); err != nil || v != 10 || loads != 1 {
+		t.Errorf("expect 10 with nil loaded once,got %v with %v loaded %d times", v, err, loads)
+	}
+
+	errLoad := errors.New("load failed")
+	v, err := c.GetOrLoad(ctx, 2, func(ctx context.Context, key int) (int, error) {
+		return 0, errLoad
+	})
+	if !errors.Is(err, errLoad) || v != 0 {
+		t.Errorf("expect 0 with %v,got %v with %v", errLoad, v, err)
+	}
+	if ok := c.Contains(2); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+
+	// callers asking for the same key while a load is in flight share it
+	const callers = 8
+	var (
+		ready, done sync.WaitGroup
+		calls       int32
+		started     = make(chan struct{})
+		release     = make(chan struct{})
+	)
+	slow := func(ctx context.Context, key int) (int, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return 30, nil
+	}
+	get := func() {
+		defer done.Done()
+		if v, err := c.GetOrLoad(ctx, 3, slow); err != nil || v != 30 {
+			t.Errorf("expect 30 with nil,got %v with %v", v, err)
+		}
+	}
+	done.Add(callers)
+	go get()
+	<-started
+	ready.Add(callers - 1)
+	for i := 1; i < callers; i++ {
+		go func() {
+			ready.Done()
+			get()
+		}()
+	}
+	ready.Wait()
+	// give the callers the chance to reach the load in flight before it finishes
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	done.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expect loader run once,got %d", n)
+	}
+
+	// a cancelled caller stops waiting without affecting the load
+	cancelled, cancel := context.WithCancel(ctx)
+	started, finish := make(chan struct{}), make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(cancelled, 4, func(ctx context.Context, key int) (int, error) {
+			close(started)
+			<-finish
+			return 40, ctx.Err()
+		})
+		result <- err
+	}()
+	<-started
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v,got %v", context.Canceled, err)
+	}
+	close(finish)
+	if v, err := c.GetOrLoad(ctx, 4, loader); err != nil || v != 40 {
+		t.Errorf("expect 40 with nil,got %v with %v", v, err)
+	}
+}
+
+func testKeys(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if keys := c.Keys(); len(keys) != 0 {
+		t.Errorf("expect no keys,got %v", keys)
+	}
+	for i := 0; i < 6; i++ {
+		c.Set(i, i)
+	}
+	keys := c.Keys()
+	if len(keys) != c.Len() {
+		t.Fatalf("expect %d keys,got %v", c.Len(), keys)
+	}
+	seen := make(map[int]bool)
+	for _, k := range keys {
+		if seen[k] {
+			t.Errorf("expect unique keys,got %v", keys)
+		}
+		seen[k] = true
+		if !c.Contains(k) {
+			t.Errorf("expect %d in cache", k)
+		}
+	}
+}
+
+func testAll(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	for i := 0; i < 6; i++ {
+		c.Set(i, i*10)
+	}
+	c.Get(2)
+	c.Get(4)
+	keys := c.Keys()
+	hits, misses, _, _ := c.Info()
+	var seen []int
+	for k, v := range c.All() {
+		if v != k*10 {
+			t.Errorf("expect %d,got %d", k*10, v)
+		}
+		seen = append(seen, k)
+		// modify the cache in the loop is safe
+		c.Remove(k)
+	}
+	if len(seen) != len(keys) {
+		t.Fatalf("expect %v,got %v", keys, seen)
+	}
+	for i := range keys {
+		if seen[i] != keys[i] {
+			t.Errorf("expect %v,got %v", keys, seen)
+			break
+		}
+	}
+	if h, m, _, _ := c.Info(); h != hits || m != misses {
+		t.Errorf("expect %d,%d;got %d %d", hits, misses, h, m)
+	}
+	if l := c.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func testPurge(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	for i := 0; i < 6; i++ {
+		c.Set(i, i)
+	}
+	c.Purge()
+	if l := c.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+	if _, ok := c.Get(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	c.Set(1, 1)
+	if v, ok := c.Get(1); !ok || v != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", v, ok)
+	}
+}
+
+func testInfo(t *testing.T, newCache Factory) {
+	c := newCache(8)
+	if hits, misses, maxSize, currentSize := c.Info(); hits != 0 || misses != 0 || maxSize != 8 || currentSize != 0 {
+		t.Errorf("expect 0,0,8,0;got %d %d %d %d", hits, misses, maxSize, currentSize)
+	}
+	c.Set(1, 1)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	if hits, misses, maxSize, currentSize := c.Info(); hits != 2 || misses != 1 || maxSize != 8 || currentSize != 1 {
+		t.Errorf("expect 2,1,8,1;got %d %d %d %d", hits, misses, maxSize, currentSize)
+	}
+}
+
+func testStats(t *testing.T, newCache Factory) {
+	ctx := context.Background()
+	c := newCache(2)
+	c.Set(1, 10)
+	c.Set(1, 11)
+	c.Set(2, 20)
+	c.Set(3, 30)
+	c.Get(3)
+	c.Get(4)
+	c.Remove(3)
+	c.GetOrLoad(ctx, 5, func(ctx context.Context, key int) (int, error) {
+		return 50, nil
+	})
+	c.GetOrLoad(ctx, 6, func(ctx context.Context, key int) (int, error) {
+		return 0, errors.New("load failed")
+	})
+	remain := c.Len()
+	c.Purge()
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 3 || s.Sets != 5 || s.LoadSuccesses != 1 || s.LoadFailures != 1 {
+		t.Errorf("expect 1 hits,3 misses,5 sets,1 load successes,1 load failures;got %+v", s)
+	}
+	expect := map[cache.EvictReason]uint64{
+		cache.EvictCapacity: 1,
+		cache.EvictRemoved:  1,
+		cache.EvictPurged:   uint64(remain),
+		cache.EvictExpired:  0,
+		cache.EvictReplaced: 1,
+	}
+	for r, n := range expect {
+		if s.Evictions[r] != n {
+			t.Errorf("expect %d %v evictions,got %v", n, r, s.Evictions)
+		}
+	}
+	// the statistics are kept by Purge but not by ResetStats
+	if hits, misses, _, _ := c.Info(); hits != 1 || misses != 3 {
+		t.Errorf("expect 1,3;got %d %d", hits, misses)
+	}
+	c.ResetStats()
+	s = c.Stats()
+	if s.Hits != 0 || s.Misses != 0 || s.Sets != 0 || s.LoadSuccesses != 0 || s.LoadFailures != 0 || s.LoadTime != 0 {
+		t.Errorf("expect zero statistics,got %+v", s)
+	}
+	for r, n := range s.Evictions {
+		if n != 0 {
+			t.Errorf("expect no %v evictions,got %d", r, n)
+		}
+	}
+}
+
+// EvictFactory return an empty cache with the given capacity which reports evictions to onEvict
+type EvictFactory func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int]
+
+// eviction is an eviction reported to the callback
+type eviction struct {
+	key, value int
+	reason     cache.EvictReason
+}
+
+// RunOnEvict runs the conformance test suite of eviction callback against caches created by newCache
+func RunOnEvict(t *testing.T, newCache EvictFactory) {
+	t.Helper()
+	var (
+		c      cache.Cache[int, int]
+		events []eviction
+	)
+	c = newCache(4, func(key, value int, reason cache.EvictReason) {
+		// the callback runs after the lock is released so calling back into the cache shall not deadlock
+		c.Contains(key)
+		events = append(events, eviction{key, value, reason})
+	})
+	expect := func(want ...eviction) {
+		t.Helper()
+		if len(events) != len(want) {
+			t.Fatalf("expect %v,got %v", want, events)
+		}
+		for i := range want {
+			if events[i] != want[i] {
+				t.Errorf("expect %v,got %v", want, events)
+				break
+			}
+		}
+		events = events[:0]
+	}
+
+	for i := 1; i <= 4; i++ {
+		c.Set(i, i*10)
+	}
+	expect()
+	c.Set(1, 11)
+	expect(eviction{1, 10, cache.EvictReplaced})
+
+	if evicted := c.Set(5, 50); !evicted {
+		t.Fatalf("expect true,got %v", evicted)
+	}
+	if len(events) != 1 || events[0].reason != cache.EvictCapacity || c.Contains(events[0].key) {
+		t.Fatalf("expect one capacity eviction of an item not in cache,got %v", events)
+	}
+	events = events[:0]
+
+	c.Remove(5)
+	expect(eviction{5, 50, cache.EvictRemoved})
+	c.Remove(5)
+	expect()
+
+	if k, v := c.PopOldest(); k == 0 || v == 0 {
+		t.Fatalf("expect an item,got %v,%v", k, v)
+	}
+	expect()
+
+	remain := make(map[int]int)
+	for _, k := range c.Keys() {
+		remain[k], _ = c.Get(k)
+	}
+	c.Purge()
+	if len(events) != len(remain) {
+		t.Fatalf("expect %d purged items,got %v", len(remain), events)
+	}
+	for _, e := range events {
+		if e.reason != cache.EvictPurged || remain[e.key] != e.value {
+			t.Errorf("expect purged items %v,got %v", remain, events)
+			break
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cachetest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// RunConcurrent runs a mix of every operation from several goroutines against a cache created by newCache,
+// it's meant to be run with the race detector
+func RunConcurrent(t *testing.T, newCache Factory) {
+	t.Helper()
+	const size = 64
+	c := newCache(size)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for range 1000 {
+				key := r.Intn(size * 2)
+				switch r.Intn(20) {
+				case 0, 1:
+					c.Set(key, key)
+				case 2:
+					c.Remove(key)
+				case 3:
+					if k, v := c.PopOldest(); k != v {
+						t.Errorf("expect %d,got %d", k, v)
+					}
+				case 4:
+					c.Keys()
+				case 5:
+					for k, v := range c.All() {
+						if k != v {
+							t.Errorf("expect %d,got %d", k, v)
+						}
+					}
+				case 6:
+					v, err := c.GetOrLoad(context.Background(), key, func(ctx context.Context, key int) (int, error) {
+						return key, nil
+					})
+					if err != nil || v != key {
+						t.Errorf("expect %d with nil,got %d with %v", key, v, err)
+					}
+				case 7:
+					if v, ok := c.GetOrSet(key, key); v != key {
+						t.Errorf("expect %d,got %d with %v", key, v, ok)
+					}
+				default:
+					if v, ok := c.Get(key); ok && v != key {
+						t.Errorf("expect %d,got %d", key, v)
+					}
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	if l := c.Len(); l > size || l != len(c.Keys()) {
+		t.Errorf("expect at most %d items as many as keys,got %d with %v", size, l, c.Keys())
+	}
+}
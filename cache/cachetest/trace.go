@@ -0,0 +1,44 @@
+package cachetest
+
+import (
+	"math/rand"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// Zipf return a trace of n keys in [0, keys) drawn from the Zipf distribution of exponent s which shall be greater
+// than 1, key 0 is the most popular, the same seed always return the same trace
+func Zipf(seed int64, s float64, keys uint64, n int) []int {
+	z := rand.NewZipf(rand.New(rand.NewSource(seed)), s, 1, keys-1)
+	trace := make([]int, n)
+	for i := range trace {
+		trace[i] = int(z.Uint64())
+	}
+	return trace
+}
+
+// Scan return a trace of the n keys from start, each requested once as a sequential scan does
+func Scan(start, n int) []int {
+	trace := make([]int, n)
+	for i := range trace {
+		trace[i] = start + i
+	}
+	return trace
+}
+
+// HitRatio replay trace against c and return the share of hits, a key is set into c after a miss as a cache aside
+// caller does
+func HitRatio(c cache.Cache[int, int], trace []int) float64 {
+	if len(trace) == 0 {
+		return 0
+	}
+	var hits int
+	for _, k := range trace {
+		if _, ok := c.Get(k); ok {
+			hits++
+			continue
+		}
+		c.Set(k, k)
+	}
+	return float64(hits) / float64(len(trace))
+}
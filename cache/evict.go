@@ -0,0 +1,39 @@
+package cache
+
+// EvictReason tell why an item left the cache
+type EvictReason int
+
+const (
+	// EvictCapacity means the item was evicted to make room for another one
+	EvictCapacity EvictReason = iota
+	// EvictRemoved means the item was removed by Remove
+	EvictRemoved
+	// EvictPurged means the item was cleared by Purge
+	EvictPurged
+	// EvictExpired means the item has been dropped after its TTL passed
+	EvictExpired
+	// EvictReplaced means the item's value was replaced by Set
+	EvictReplaced
+)
+
+// return the name of reason
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictRemoved:
+		return "removed"
+	case EvictPurged:
+		return "purged"
+	case EvictExpired:
+		return "expired"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictCallback is called with the key and value that left the cache, it's invoked after the cache lock is released
+// so it's free to call back into the cache
+type EvictCallback[K comparable, V any] func(key K, value V, reason EvictReason)
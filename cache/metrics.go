@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StatsVar expose the statistics returned by its func as an expvar.Var, e.g.
+//
+//	expvar.Publish("users_cache", cache.StatsVar(users.Stats))
+type StatsVar func() Stats
+
+// String return the statistics in JSON
+func (v StatsVar) String() string {
+	s := v()
+	evictions := make(map[string]uint64, len(s.Evictions))
+	for r, n := range s.Evictions {
+		evictions[r.String()] = n
+	}
+	b, _ := json.Marshal(struct {
+		Hits            uint64            `json:"hits"`
+		Misses          uint64            `json:"misses"`
+		Sets            uint64            `json:"sets"`
+		Evictions       map[string]uint64 `json:"evictions"`
+		Expirations     uint64            `json:"expirations"`
+		LoadSuccesses   uint64            `json:"load_successes"`
+		LoadFailures    uint64            `json:"load_failures"`
+		LoadTimeSeconds float64           `json:"load_time_seconds"`
+	}{
+		Hits:            s.Hits,
+		Misses:          s.Misses,
+		Sets:            s.Sets,
+		Evictions:       evictions,
+		Expirations:     s.Expirations(),
+		LoadSuccesses:   s.LoadSuccesses,
+		LoadFailures:    s.LoadFailures,
+		LoadTimeSeconds: s.LoadTime.Seconds(),
+	})
+	return string(b)
+}
+
+// WritePrometheus write the statistics of caches keyed by name in the Prometheus text exposition format,
+// every sample is labeled by cache="<name>"
+func WritePrometheus(w io.Writer, caches map[string]Stats) error {
+	names := make([]string, 0, len(caches))
+	for name := range caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	counter := func(metric, help string, value func(s Stats) uint64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n", metric, help, metric)
+		for _, name := range names {
+			fmt.Fprintf(bw, "%s{cache=\"%s\"} %d\n", metric, escapeLabel(name), value(caches[name]))
+		}
+	}
+	counter("cache_hits_total", "The number of lookups found the key.", func(s Stats) uint64 { return s.Hits })
+	counter("cache_misses_total", "The number of lookups didn't find the key.", func(s Stats) uint64 { return s.Misses })
+	counter("cache_sets_total", "The number of items added or updated.", func(s Stats) uint64 { return s.Sets })
+
+	fmt.Fprint(bw, "# HELP cache_evictions_total The number of items left the cache by reason.\n# TYPE cache_evictions_total counter\n")
+	for _, name := range names {
+		for r := EvictReason(0); int(r) < evictReasons; r++ {
+			fmt.Fprintf(bw, "cache_evictions_total{cache=\"%s\",reason=\"%s\"} %d\n", escapeLabel(name), r, caches[name].Evictions[r])
+		}
+	}
+
+	counter("cache_load_successes_total", "The number of loads succeeded.", func(s Stats) uint64 { return s.LoadSuccesses })
+	counter("cache_load_failures_total", "The number of loads failed.", func(s Stats) uint64 { return s.LoadFailures })
+	fmt.Fprint(bw, "# HELP cache_load_duration_seconds_total The total time spent loading.\n# TYPE cache_load_duration_seconds_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(bw, "cache_load_duration_seconds_total{cache=\"%s\"} %g\n", escapeLabel(name), caches[name].LoadTime.Seconds())
+	}
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escape backslash, double quote and line feed in label value
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
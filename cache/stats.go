@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// the number of EvictReason
+const evictReasons = int(EvictReplaced) + 1
+
+// Stats is a snapshot of the cache statistics
+type Stats struct {
+	// Hits and Misses count the lookups by Get, GetOrSet and GetOrLoad
+	Hits   uint64
+	Misses uint64
+	// Sets count the items added or updated
+	Sets uint64
+	// Evictions count the items left the cache by reason, every reason is present
+	Evictions map[EvictReason]uint64
+	// LoadSuccesses and LoadFailures count the loader runs of GetOrLoad, LoadTime is the total time they took
+	LoadSuccesses uint64
+	LoadFailures  uint64
+	LoadTime      time.Duration
+}
+
+// Expirations return the number of items dropped after their TTL passed
+func (s Stats) Expirations() uint64 {
+	return s.Evictions[EvictExpired]
+}
+
+// HitRatio return hits over lookups, zero if there is no lookup
+func (s Stats) HitRatio() float64 {
+	lookups := s.Hits + s.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(lookups)
+}
+
+// Add return the sum of s and other, useful to aggregate the statistics of several caches
+func (s Stats) Add(other Stats) Stats {
+	sum := Stats{
+		Hits:          s.Hits + other.Hits,
+		Misses:        s.Misses + other.Misses,
+		Sets:          s.Sets + other.Sets,
+		Evictions:     make(map[EvictReason]uint64, evictReasons),
+		LoadSuccesses: s.LoadSuccesses + other.LoadSuccesses,
+		LoadFailures:  s.LoadFailures + other.LoadFailures,
+		LoadTime:      s.LoadTime + other.LoadTime,
+	}
+	for r := EvictReason(0); int(r) < evictReasons; r++ {
+		sum.Evictions[r] = s.Evictions[r] + other.Evictions[r]
+	}
+	return sum
+}
+
+// Recorder maintains the cache statistics atomically so they can be read without the cache lock,
+// the zero value is ready to use
+type Recorder struct {
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	sets          atomic.Uint64
+	evictions     [evictReasons]atomic.Uint64
+	loadSuccesses atomic.Uint64
+	loadFailures  atomic.Uint64
+	loadTime      atomic.Int64
+}
+
+// Hit record a lookup found the key
+func (r *Recorder) Hit() {
+	r.hits.Add(1)
+}
+
+// Miss record a lookup didn't find the key
+func (r *Recorder) Miss() {
+	r.misses.Add(1)
+}
+
+// Set record an item added or updated
+func (r *Recorder) Set() {
+	r.sets.Add(1)
+}
+
+// Evict record an item left the cache for reason
+func (r *Recorder) Evict(reason EvictReason) {
+	if reason >= 0 && int(reason) < evictReasons {
+		r.evictions[reason].Add(1)
+	}
+}
+
+// Load record a loader run took d, it failed if err isn't nil
+func (r *Recorder) Load(d time.Duration, err error) {
+	if err != nil {
+		r.loadFailures.Add(1)
+	} else {
+		r.loadSuccesses.Add(1)
+	}
+	r.loadTime.Add(int64(d))
+}
+
+// Stats return a snapshot of the statistics recorded so far
+func (r *Recorder) Stats() Stats {
+	s := Stats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Sets:          r.sets.Load(),
+		Evictions:     make(map[EvictReason]uint64, evictReasons),
+		LoadSuccesses: r.loadSuccesses.Load(),
+		LoadFailures:  r.loadFailures.Load(),
+		LoadTime:      time.Duration(r.loadTime.Load()),
+	}
+	for i := range r.evictions {
+		s.Evictions[EvictReason(i)] = r.evictions[i].Load()
+	}
+	return s
+}
+
+// Reset set all the statistics to zero
+func (r *Recorder) Reset() {
+	r.hits.Store(0)
+	r.misses.Store(0)
+	r.sets.Store(0)
+	for i := range r.evictions {
+		r.evictions[i].Store(0)
+	}
+	r.loadSuccesses.Store(0)
+	r.loadFailures.Store(0)
+	r.loadTime.Store(0)
+}
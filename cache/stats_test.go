@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Stats(t *testing.T) {
+	var r Recorder
+	r.Hit()
+	r.Hit()
+	r.Miss()
+	r.Set()
+	r.Evict(EvictExpired)
+	r.Evict(EvictCapacity)
+	r.Evict(EvictReason(-1))
+	r.Load(time.Second, nil)
+	r.Load(time.Second, errors.New("load failed"))
+	s := r.Stats()
+	if s.Hits != 2 || s.Misses != 1 || s.Sets != 1 || s.LoadSuccesses != 1 || s.LoadFailures != 1 || s.LoadTime != 2*time.Second {
+		t.Errorf("expect 2,1,1,1,1,2s;got %+v", s)
+	}
+	if s.Expirations() != 1 || s.Evictions[EvictCapacity] != 1 || len(s.Evictions) != evictReasons {
+		t.Errorf("expect 1 expired and 1 capacity evictions,got %v", s.Evictions)
+	}
+	if ratio := s.HitRatio(); ratio < 0.66 || ratio > 0.67 {
+		t.Errorf("expect 0.67,got %v", ratio)
+	}
+	r.Reset()
+	if s := r.Stats(); s.Hits != 0 || s.Expirations() != 0 || s.LoadTime != 0 || s.HitRatio() != 0 {
+		t.Errorf("expect zero statistics,got %+v", s)
+	}
+}
+
+func TestStats_Add(t *testing.T) {
+	var a, b Recorder
+	a.Hit()
+	a.Evict(EvictRemoved)
+	b.Hit()
+	b.Miss()
+	b.Evict(EvictRemoved)
+	s := Stats{}.Add(a.Stats()).Add(b.Stats())
+	if s.Hits != 2 || s.Misses != 1 || s.Evictions[EvictRemoved] != 2 {
+		t.Errorf("expect 2,1,2;got %+v", s)
+	}
+}
+
+func TestStatsVar_String(t *testing.T) {
+	var r Recorder
+	r.Hit()
+	r.Evict(EvictExpired)
+	r.Load(time.Second/2, nil)
+	var v struct {
+		Hits            uint64            `json:"hits"`
+		Evictions       map[string]uint64 `json:"evictions"`
+		Expirations     uint64            `json:"expirations"`
+		LoadTimeSeconds float64           `json:"load_time_seconds"`
+	}
+	if err := json.Unmarshal([]byte(StatsVar(r.Stats).String()), &v); err != nil {
+		t.Fatalf("expect JSON,got %v", err)
+	}
+	if v.Hits != 1 || v.Evictions["expired"] != 1 || v.Expirations != 1 || v.LoadTimeSeconds != 0.5 {
+		t.Errorf("expect 1,1,1,0.5;got %+v", v)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var users, orders Recorder
+	users.Hit()
+	users.Evict(EvictCapacity)
+	orders.Miss()
+	orders.Load(1500*time.Millisecond, nil)
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, map[string]Stats{"users": users.Stats(), `or"ders`: orders.Stats()}); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	expect := `# HELP cache_hits_total The number of lookups found the key.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="or\"ders"} 0
+cache_hits_total{cache="users"} 1
+# HELP cache_misses_total The number of lookups didn't find the key.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="or\"ders"} 1
+cache_misses_total{cache="users"} 0
+# HELP cache_sets_total The number of items added or updated.
+# TYPE cache_sets_total counter
+cache_sets_total{cache="or\"ders"} 0
+cache_sets_total{cache="users"} 0
+# HELP cache_evictions_total The number of items left the cache by reason.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="or\"ders",reason="capacity"} 0
+cache_evictions_total{cache="or\"ders",reason="removed"} 0
+cache_evictions_total{cache="or\"ders",reason="purged"} 0
+cache_evictions_total{cache="or\"ders",reason="expired"} 0
+cache_evictions_total{cache="or\"ders",reason="replaced"} 0
+cache_evictions_total{cache="users",reason="capacity"} 1
+cache_evictions_total{cache="users",reason="removed"} 0
+cache_evictions_total{cache="users",reason="purged"} 0
+cache_evictions_total{cache="users",reason="expired"} 0
+cache_evictions_total{cache="users",reason="replaced"} 0
+# HELP cache_load_successes_total The number of loads succeeded.
+# TYPE cache_load_successes_total counter
+cache_load_successes_total{cache="or\"ders"} 1
+cache_load_successes_total{cache="users"} 0
+# HELP cache_load_failures_total The number of loads failed.
+# TYPE cache_load_failures_total counter
+cache_load_failures_total{cache="or\"ders"} 0
+cache_load_failures_total{cache="users"} 0
+# HELP cache_load_duration_seconds_total The total time spent loading.
+# TYPE cache_load_duration_seconds_total counter
+cache_load_duration_seconds_total{cache="or\"ders"} 1.5
+cache_load_duration_seconds_total{cache="users"} 0
+`
+	if got := buf.String(); got != expect {
+		t.Errorf("expect\n%s\ngot\n%s", expect, got)
+	}
+}
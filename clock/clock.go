@@ -0,0 +1,317 @@
+// Package clock implement CLOCK, the second chance approximation of LRU
+// https://en.wikipedia.org/wiki/Page_replacement_algorithm#Clock
+// items are kept around a clock and a hit only sets the reference bit of the item atomically, so Get looks the item up
+// in a concurrent index without taking the lock and never moves the item, a hand goes around the clock clearing the
+// bits and evicts the first item whose bit is clear, the new item takes its place just behind the hand
+package clock
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default CLOCK size
+	Default_CLOCK_Size = 1024
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// Cache implements a thread safe fixed size CLOCK cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	// clock hold items in the order the hand passes them, from front to back then around to front again,
+	// hand is the item the next eviction starts from, nil only if the clock is empty
+	clock *list.List[payload[K, V]]
+	hand  *list.Element[payload[K, V]]
+	items map[K]*list.Element[payload[K, V]]
+	// index hold the *entry of every item for the hits, it's updated along with items while holding the write lock
+	index   sync.Map
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+}
+
+// payload contains the value clock hold
+type payload[K comparable, V any] struct {
+	key   K
+	entry *entry[V]
+}
+
+// entry is the part of an item touched by the hits without the lock, the value is never modified so a new entry
+// replaces the old one when the key is set again
+type entry[V any] struct {
+	value      V
+	referenced atomic.Uint32
+}
+
+// New return a given size CLOCK holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_CLOCK_Size
+	}
+	c := &Cache[K, V]{
+		capacity: size,
+		clock:    list.New[payload[K, V]](),
+		items:    make(map[K]*list.Element[payload[K, V]]),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// return the CLOCK running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the CLOCK statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the CLOCK statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the CLOCK max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into CLOCK just behind the hand, the item the hand stops at is evicted if CLOCK is full
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	// key has exists, update it to new value
+	if v, ok := c.items[key]; ok {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.entry.value, cache.EvictReplaced)
+		v.Value.entry = &entry[V]{value: value}
+		v.Value.entry.referenced.Store(1)
+		c.index.Store(key, v.Value.entry)
+		return false
+	}
+	if len(c.items) >= c.capacity {
+		c.evict(c.victim(), cache.EvictCapacity)
+		evicted = true
+	}
+	v := payload[K, V]{key: key, entry: &entry[V]{value: value}}
+	c.index.Store(key, v.entry)
+	if c.hand == nil {
+		c.hand = c.clock.PushBack(v)
+		c.items[key] = c.hand
+		return evicted
+	}
+	c.items[key] = c.clock.InsertBefore(v, c.hand)
+	return evicted
+}
+
+// Get value from CLOCK by key, no lock is taken
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	v, ok := c.index.Load(key)
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	e := v.(*entry[V])
+	// skip the store if it's set already, so the cache line isn't written by every hit
+	if e.referenced.Load() == 0 {
+		e.referenced.Store(1)
+	}
+	c.stats.Hit()
+	return e.value, ok
+}
+
+// Cotains check if the CLOCK contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics, no lock is taken
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	v, ok := c.index.Load(key)
+	if !ok {
+		return value, false
+	}
+	return v.(*entry[V]).value, true
+}
+
+// Remove the given key item return if the key has existed before
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	v, ok := c.items[key]
+	if ok {
+		c.evict(v, cache.EvictRemoved)
+	}
+	return ok
+}
+
+// Remove and return the item CLOCK would evict next, the hand moves to it clearing the bits on the way as an
+// eviction does, the item is handed to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the CLOCK is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if c.clock.Len() == 0 {
+		return key, value, false
+	}
+	v := c.victim()
+	c.removeItem(v)
+	return v.Value.key, v.Value.entry.value, true
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.Get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into CLOCK
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the CLOCK hold in the order they would be evicted if no item were touched, the unreferenced ones
+// from the hand around the clock, then the referenced ones in the same order
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for _, v := range c.order() {
+		keys = append(keys, v.Value.key)
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without setting their reference bits, the items are
+// taken when the iteration starts so it's safe to modify the CLOCK in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(c.items))
+	for _, v := range c.order() {
+		items = append(items, v.Value)
+	}
+	return items
+}
+
+// return the CLOCK length
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items)
+}
+
+// Purge use to clear all items in CLOCK, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, v := range c.order() {
+		c.stats.Evict(cache.EvictPurged)
+		c.evicted.Add(v.Value.key, v.Value.entry.value, cache.EvictPurged)
+	}
+	c.clock.Init()
+	c.hand = nil
+	clear(c.items)
+	c.index.Clear()
+}
+
+// return the items in the order of Keys, the lock must be held
+func (c *Cache[K, V]) order() []*list.Element[payload[K, V]] {
+	items := make([]*list.Element[payload[K, V]], 0, c.clock.Len())
+	for _, referenced := range []uint32{0, 1} {
+		v := c.hand
+		for range c.clock.Len() {
+			if v.Value.entry.referenced.Load() == referenced {
+				items = append(items, v)
+			}
+			v = c.next(v)
+		}
+	}
+	return items
+}
+
+// victim move the hand to the item to be evicted and return it, the reference bits on the way are cleared,
+// CLOCK shall not be empty
+func (c *Cache[K, V]) victim() *list.Element[payload[K, V]] {
+	v := c.hand
+	for v.Value.entry.referenced.Load() == 1 {
+		v.Value.entry.referenced.Store(0)
+		v = c.next(v)
+	}
+	c.hand = v
+	return v
+}
+
+// return the item after e around the clock
+func (c *Cache[K, V]) next(e *list.Element[payload[K, V]]) *list.Element[payload[K, V]] {
+	if v := e.Next(); v != nil {
+		return v
+	}
+	return c.clock.Front()
+}
+
+// remove item from CLOCK and notify the eviction callback
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.removeItem(e)
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.entry.value, reason)
+}
+
+// remove item from CLOCK, the hand moves to the next item if it points to the removed one
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	if c.hand == e {
+		c.hand = c.next(e)
+		if c.hand == e {
+			c.hand = nil
+		}
+	}
+	c.clock.Remove(e)
+	delete(c.items, e.Value.key)
+	c.index.Delete(e.Value.key)
+}
@@ -0,0 +1,82 @@
+package clock
+
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCLOCK_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestCLOCK_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestCLOCK_Hand(t *testing.T) {
+	c := New[int, int](3)
+	for i := 1; i <= 3; i++ {
+		c.Set(i, i)
+	}
+	c.Get(1)
+	c.Get(2)
+	if diff := cmp.Diff([]int{3, 1, 2}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the hand clears the bits of 1 and 2 then evicts 3, 4 takes its place behind the hand
+	c.Set(4, 4)
+	if diff := cmp.Diff([]int{1, 2, 4}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// 1 gets a second chance and 2 is evicted
+	c.Get(1)
+	c.Set(5, 5)
+	if diff := cmp.Diff([]int{4, 1, 5}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	if k, v := c.PopOldest(); k != 4 || v != 4 {
+		t.Errorf("expect 4,4;got %v,%v", k, v)
+	}
+	if diff := cmp.Diff([]int{1, 5}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCLOCK_Concurrent(t *testing.T) {
+	cachetest.RunConcurrent(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func BenchmarkCLOCK_Set(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+}
+
+func BenchmarkCLOCK_GetExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	c.Set(1, 1)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
+
+// BenchmarkCLOCK_ParallelGet is compared with BenchmarkLRU_ParallelGet, Get of CLOCK takes no lock
+func BenchmarkCLOCK_ParallelGet(b *testing.B) {
+	cachetest.ParallelGet(b, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
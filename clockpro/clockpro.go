@@ -0,0 +1,497 @@
+// Package clockpro implement CLOCK-Pro as described in "CLOCK-Pro: An Effective Improvement of the CLOCK Replacement"
+// by Song Jiang, Feng Chen and Xiaodong Zhang https://www.usenix.org/legacy/event/usenix05/tech/general/full_papers/jiang/jiang.pdf
+// items are hot or cold and the keys of cold items evicted during their test period are kept as non resident test
+// items, all of them around a single clock swept by three hands, a hit only sets the reference bit of the item
+// atomically so Get looks the item up in a concurrent index without taking the lock, a cold item referenced during
+// its test period is promoted to hot and the target number of cold items adapts to how often the cold items are
+// referenced during their test period, approximating ARC the way CLOCK does LRU, unlike the paper an item stays where
+// it is in the clock when its status changes
+package clockpro
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default CLOCK-Pro size
+	Default_CLOCKPro_Size = 1024
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// status tell whether an item is hot, cold or a test item whose value has been evicted
+type status uint8
+
+const (
+	cold status = iota
+	hot
+	test
+)
+
+// Cache implements a thread safe fixed size CLOCK-Pro cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	// coldTarget is the adaptive target number of cold items, the m_c of the paper
+	coldTarget int
+	// the number of items of every status
+	hots, colds, tests int
+
+	// clock hold all items in the order the hands pass them, from front to back then around to front again,
+	// every hand is the item it moves from next, they are nil only if the clock is empty, new items are inserted
+	// just behind the hot hand so every hand reaches them last
+	clock    *list.List[payload[K, V]]
+	handHot  *list.Element[payload[K, V]]
+	handCold *list.Element[payload[K, V]]
+	handTest *list.Element[payload[K, V]]
+	items    map[K]*list.Element[payload[K, V]]
+	// index hold the *entry of every hot and cold item for the hits, it's updated along with items while holding
+	// the write lock
+	index sync.Map
+
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+}
+
+// payload contains the value clock hold
+type payload[K comparable, V any] struct {
+	key    K
+	status status
+	// testing tell if a cold item is in its test period, which starts when it's added and ends when the hot or the
+	// test hand passes it, a test item is always in its test period
+	testing bool
+	// entry is nil for the test items
+	entry *entry[V]
+}
+
+// entry is the part of an item touched by the hits without the lock, the value is never modified so a new entry
+// replaces the old one when the key is set again
+type entry[V any] struct {
+	value      V
+	referenced atomic.Uint32
+}
+
+// New return a given size CLOCK-Pro holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_CLOCKPro_Size
+	}
+	c := &Cache[K, V]{
+		capacity:   size,
+		coldTarget: size,
+		clock:      list.New[payload[K, V]](),
+		items:      make(map[K]*list.Element[payload[K, V]]),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// return the CLOCK-Pro running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the CLOCK-Pro statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the CLOCK-Pro statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the CLOCK-Pro max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into CLOCK-Pro as a cold item, or as a hot one if its key is kept as a test item,
+// a cold item is evicted if CLOCK-Pro is full
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	v, ok := c.items[key]
+	// key has exists, update it to new value
+	if ok && v.Value.status != test {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.entry.value, cache.EvictReplaced)
+		v.Value.entry = &entry[V]{value: value}
+		v.Value.entry.referenced.Store(1)
+		c.index.Store(key, v.Value.entry)
+		return false
+	}
+	evicted = c.hots+c.colds >= c.capacity
+	if ok {
+		// the key is set again during its test period, so more cold items would have made it a hit
+		c.coldTarget = min(c.coldTarget+1, c.capacity)
+		c.removeItem(v)
+		c.add(key, value, hot)
+		return evicted
+	}
+	c.add(key, value, cold)
+	return evicted
+}
+
+// Get value from CLOCK-Pro by key, no lock is taken
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	v, ok := c.index.Load(key)
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	e := v.(*entry[V])
+	// skip the store if it's set already, so the cache line isn't written by every hit
+	if e.referenced.Load() == 0 {
+		e.referenced.Store(1)
+	}
+	c.stats.Hit()
+	return e.value, ok
+}
+
+// Cotains check if the CLOCK-Pro contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics, no lock is taken
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	v, ok := c.index.Load(key)
+	if !ok {
+		return value, false
+	}
+	return v.(*entry[V]).value, true
+}
+
+// Remove the item from cache by key, a key only kept as a test item is forgotten but reported as not existed
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	v, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if v.Value.status == test {
+		c.removeItem(v)
+		return false
+	}
+	c.evict(v, cache.EvictRemoved)
+	return true
+}
+
+// Remove and return the item CLOCK-Pro would evict next, the hands run to it as an eviction does, the item is handed
+// to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the CLOCK-Pro is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if c.hots+c.colds == 0 {
+		return key, value, false
+	}
+	v := c.victim()
+	c.removeItem(v)
+	c.balance()
+	return v.Value.key, v.Value.entry.value, true
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.Get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into CLOCK-Pro
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the CLOCK-Pro hold, the cold ones from the cold hand around the clock with the unreferenced ones
+// first, then the hot ones from the hot hand in the same way
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, c.hots+c.colds)
+	for _, v := range c.order() {
+		keys = append(keys, v.Value.key)
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without setting their reference bits, the items are
+// taken when the iteration starts so it's safe to modify the CLOCK-Pro in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, c.hots+c.colds)
+	for _, v := range c.order() {
+		items = append(items, v.Value)
+	}
+	return items
+}
+
+// return the CLOCK-Pro length, the test items are not counted
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.hots + c.colds
+}
+
+// Purge use to clear all items and test items in CLOCK-Pro, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, v := range c.order() {
+		c.stats.Evict(cache.EvictPurged)
+		c.evicted.Add(v.Value.key, v.Value.entry.value, cache.EvictPurged)
+	}
+	c.clock.Init()
+	c.handHot, c.handCold, c.handTest = nil, nil, nil
+	clear(c.items)
+	c.index.Clear()
+	c.hots, c.colds, c.tests = 0, 0, 0
+	c.coldTarget = c.capacity
+}
+
+// return the items in the order of Keys, the lock must be held
+func (c *Cache[K, V]) order() []*list.Element[payload[K, V]] {
+	items := make([]*list.Element[payload[K, V]], 0, c.hots+c.colds)
+	for _, s := range []status{cold, hot} {
+		hand := c.handCold
+		if s == hot {
+			hand = c.handHot
+		}
+		for _, referenced := range []uint32{0, 1} {
+			v := hand
+			for range c.clock.Len() {
+				if v.Value.status == s && v.Value.entry.referenced.Load() == referenced {
+					items = append(items, v)
+				}
+				v = c.next(v)
+			}
+		}
+	}
+	return items
+}
+
+// add insert an item of status just behind the hot hand after making room for it
+func (c *Cache[K, V]) add(key K, value V, s status) {
+	for c.hots+c.colds >= c.capacity {
+		c.evict(c.victim(), cache.EvictCapacity)
+	}
+	v := payload[K, V]{key: key, status: s, testing: s == cold, entry: &entry[V]{value: value}}
+	c.index.Store(key, v.entry)
+	if c.handHot == nil {
+		e := c.clock.PushBack(v)
+		c.handHot, c.handCold, c.handTest = e, e, e
+		c.items[key] = e
+	} else {
+		c.items[key] = c.clock.InsertBefore(v, c.handHot)
+	}
+	c.count(s, 1)
+	c.balance()
+}
+
+// victim return the cold item to be evicted next, a hot item is demoted to be the one if there is no cold item,
+// CLOCK-Pro shall not be empty
+func (c *Cache[K, V]) victim() *list.Element[payload[K, V]] {
+	if e := c.runHandCold(); e != nil {
+		return e
+	}
+	return c.runHandHot()
+}
+
+// balance run the hot hand until the hot items fit the room the cold target leaves, then the test hand until there
+// are no more test items than the capacity
+func (c *Cache[K, V]) balance() {
+	for c.hots > c.capacity-c.coldTarget {
+		if c.runHandHot() == nil {
+			return
+		}
+	}
+	for c.tests > c.capacity {
+		if !c.runHandTest() {
+			return
+		}
+	}
+}
+
+// runHandCold move the cold hand to the first unreferenced cold item and return it, a referenced cold item on the
+// way is promoted to hot if it's in its test period, otherwise it starts a new test period, after a full turn the
+// first cold item is returned whatever its reference bit is, return nil if there is no cold item
+func (c *Cache[K, V]) runHandCold() *list.Element[payload[K, V]] {
+	turn := c.clock.Len()
+	for i := 0; i < 2*turn && c.colds > 0; i++ {
+		e := c.handCold
+		if e.Value.status == cold {
+			if i >= turn || e.Value.entry.referenced.Load() == 0 {
+				return e
+			}
+			e.Value.entry.referenced.Store(0)
+			if e.Value.testing {
+				e.Value.testing = false
+				c.setStatus(e, hot)
+			} else {
+				e.Value.testing = true
+			}
+		}
+		c.handCold = c.next(e)
+	}
+	return nil
+}
+
+// runHandHot move the hot hand past the first unreferenced hot item and demote it to cold, the referenced hot items
+// on the way get their bits cleared and the cold and test items end their test period, after a full turn the first
+// hot item is demoted whatever its reference bit is, return the demoted item or nil if there is no hot item
+func (c *Cache[K, V]) runHandHot() *list.Element[payload[K, V]] {
+	turn := c.clock.Len()
+	for i := 0; i < 2*turn && c.hots > 0; i++ {
+		e := c.handHot
+		c.handHot = c.next(e)
+		if e.Value.status != hot {
+			c.endTest(e)
+			continue
+		}
+		if i < turn && e.Value.entry.referenced.Load() == 1 {
+			e.Value.entry.referenced.Store(0)
+			continue
+		}
+		c.setStatus(e, cold)
+		return e
+	}
+	return nil
+}
+
+// runHandTest move the test hand past the first test item and remove it, the cold items on the way end their test
+// period, the hand stops after a full turn, return false if no test item is removed
+func (c *Cache[K, V]) runHandTest() bool {
+	for range c.clock.Len() {
+		e := c.handTest
+		c.handTest = c.next(e)
+		removed := e.Value.status == test
+		c.endTest(e)
+		if removed {
+			return true
+		}
+	}
+	return false
+}
+
+// endTest end the test period of a cold or test item, a test item is removed and the target number of cold items
+// shrinks unless the cold item has been referenced during the period
+func (c *Cache[K, V]) endTest(e *list.Element[payload[K, V]]) {
+	if !e.Value.testing {
+		return
+	}
+	e.Value.testing = false
+	if e.Value.status == test {
+		c.removeItem(e)
+	} else if e.Value.entry.referenced.Load() == 1 {
+		return
+	}
+	c.coldTarget = max(c.coldTarget-1, 1)
+}
+
+// setStatus change the status of item and the counts
+func (c *Cache[K, V]) setStatus(e *list.Element[payload[K, V]], s status) {
+	c.count(e.Value.status, -1)
+	e.Value.status = s
+	c.count(s, 1)
+}
+
+// count add delta to the count of status
+func (c *Cache[K, V]) count(s status, delta int) {
+	switch s {
+	case hot:
+		c.hots += delta
+	case cold:
+		c.colds += delta
+	case test:
+		c.tests += delta
+	}
+}
+
+// return the item after e around the clock
+func (c *Cache[K, V]) next(e *list.Element[payload[K, V]]) *list.Element[payload[K, V]] {
+	if v := e.Next(); v != nil {
+		return v
+	}
+	return c.clock.Front()
+}
+
+// evict the value of a cold item and notify the eviction callback, the item is kept as a test item if it's in
+// its test period, otherwise it's removed
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.entry.value, reason)
+	if !e.Value.testing || reason != cache.EvictCapacity {
+		c.removeItem(e)
+		return
+	}
+	if c.handCold == e {
+		c.handCold = c.next(e)
+	}
+	e.Value.entry = nil
+	c.index.Delete(e.Value.key)
+	c.setStatus(e, test)
+}
+
+// remove item from the clock, the hands pointing to it move to the next item
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	next := c.next(e)
+	if next == e {
+		next = nil
+	}
+	for _, hand := range []**list.Element[payload[K, V]]{&c.handHot, &c.handCold, &c.handTest} {
+		if *hand == e {
+			*hand = next
+		}
+	}
+	c.clock.Remove(e)
+	c.count(e.Value.status, -1)
+	delete(c.items, e.Value.key)
+	c.index.Delete(e.Value.key)
+}
@@ -0,0 +1,190 @@
+package clockpro
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/FelixSeptem/collections/clock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCLOCKPro_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestCLOCKPro_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestCLOCKPro_TestItems(t *testing.T) {
+	c := New[int, int](4)
+	for i := range 12 {
+		c.Set(i, i)
+	}
+	// without hits the cold hand evicts the items in the order they are set
+	if diff := cmp.Diff([]int{8, 9, 10, 11}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the evicted cold items are kept as test items, the oldest ones end their test period without being set
+	// again when there are more test items than the capacity, so the cold target shrinks
+	if c.Contains(7) || c.items[7].Value.status != test || c.tests != 4 || c.coldTarget != 1 {
+		t.Fatalf("expect 7 kept as a test item with cold target 1,got %+v with %d test items and cold target %d",
+			c.items[7].Value, c.tests, c.coldTarget)
+	}
+	if _, ok := c.items[3]; ok {
+		t.Errorf("expect 3 forgotten,got %+v", c.items[3].Value)
+	}
+	if v, ok := c.Get(7); ok {
+		t.Errorf("expect a miss of test item,got %v", v)
+	}
+	// a test item set again during its test period becomes hot and the cold target grows
+	c.Set(7, 7)
+	if v := c.items[7].Value; v.status != hot || v.entry.value != 7 || c.Len() != 4 || c.coldTarget != 2 {
+		t.Errorf("expect 7 is hot with cold target 2,got %+v with %d items and cold target %d", v, c.Len(), c.coldTarget)
+	}
+	if diff := cmp.Diff([]int{9, 10, 11, 7}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the hot item survives a scan
+	for _, k := range cachetest.Scan(100, 10) {
+		c.Set(k, k)
+	}
+	if v := c.items[7].Value; v.status != hot || c.tests > c.capacity {
+		t.Errorf("expect 7 survives as a hot item,got %+v with %d test items", v, c.tests)
+	}
+	if keys := c.Keys(); keys[len(keys)-1] != 7 {
+		t.Errorf("expect hot item 7 at the end of keys,got %v", keys)
+	}
+	// a test item is forgotten but reported as not existed
+	key, tests := c.handTest.Value.key, c.tests
+	for c.items[key].Value.status != test {
+		key = c.next(c.items[key]).Value.key
+	}
+	if c.Remove(key) || c.tests != tests-1 {
+		t.Errorf("expect false with %d test items,got true with %d", tests-1, c.tests)
+	}
+	c.Purge()
+	if c.Len() != 0 || c.clock.Len() != 0 || c.coldTarget != 4 {
+		t.Errorf("expect empty CLOCK-Pro,got %d items %d test items", c.Len(), c.tests)
+	}
+}
+
+func TestCLOCKPro_Stress(t *testing.T) {
+	// a hit before an insert that evicts in a CLOCK-Pro of size 1
+	c := New[int, int](1)
+	c.Set(2, 1)
+	c.Get(2)
+	c.Set(1, 1)
+	if diff := cmp.Diff([]int{1}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for size := 1; size <= 16; size++ {
+		c := New[int, int](size)
+		for i := range 5000 {
+			key := r.Intn(3 * size)
+			switch r.Intn(8) {
+			case 0:
+				c.Remove(key)
+			case 1:
+				if k, v := c.PopOldest(); k != v {
+					t.Fatalf("expect the value of %d,got %d", k, v)
+				}
+			case 2, 3, 4:
+				c.Get(key)
+			default:
+				c.Set(key, key)
+			}
+			if l, keys := c.Len(), c.Keys(); l > c.Cap() || len(keys) != l {
+				t.Fatalf("expect at most %d items as many as keys at step %d,got %d with %v", size, i, l, keys)
+			}
+			if c.hots+c.colds+c.tests != c.clock.Len() || c.tests > size || c.coldTarget < 1 || c.coldTarget > size {
+				t.Fatalf("expect consistent counts at step %d,got %d hot %d cold %d test items in a clock of %d with cold target %d",
+					i, c.hots, c.colds, c.tests, c.clock.Len(), c.coldTarget)
+			}
+		}
+	}
+}
+
+func TestCLOCKPro_Concurrent(t *testing.T) {
+	cachetest.RunConcurrent(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestCLOCKPro_ScanResistance(t *testing.T) {
+	var trace []int
+	for i := range 10 {
+		trace = append(trace, cachetest.Zipf(int64(i), 1.01, 100000, 20000)...)
+		trace = append(trace, cachetest.Scan(1000000+i*5000, 5000)...)
+	}
+	got := cachetest.HitRatio(New[int, int](1000), trace)
+	base := cachetest.HitRatio(clock.New[int, int](1000), trace)
+	t.Logf("clockpro: %.4f clock: %.4f", got, base)
+	if got <= base {
+		t.Errorf("expect CLOCK-Pro hit ratio higher than clock %.4f,got %.4f", base, got)
+	}
+}
+
+func BenchmarkCLOCKPro_Set(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+}
+
+func BenchmarkCLOCKPro_GetExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	c.Set(1, 1)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
+
+// BenchmarkCLOCKPro_ParallelGet is compared with BenchmarkLRU_ParallelGet, Get of CLOCK-Pro takes no lock
+func BenchmarkCLOCKPro_ParallelGet(b *testing.B) {
+	cachetest.ParallelGet(b, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestCLOCKPro_PopOldest(t *testing.T) {
+	c := New[int, int](4)
+	for i := range 12 {
+		c.Set(i, i)
+	}
+	c.Get(8)
+	c.Get(9)
+	if diff := cmp.Diff([]int{10, 11, 8, 9}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the cold hand promotes 8 and 9 referenced during their test period as an eviction does, then stops at 10
+	if k, v := c.PopOldest(); k != 10 || v != 10 {
+		t.Errorf("expect 10,10;got %v,%v", k, v)
+	}
+	if c.hots != 2 || c.colds != 1 || c.tests != 4 {
+		t.Errorf("expect 2 hot 1 cold and 4 test items,got %d %d %d", c.hots, c.colds, c.tests)
+	}
+	if diff := cmp.Diff([]int{11, 8, 9}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the hot hand demotes 8 when there is no cold item, the test items it passes end their test period
+	c.Remove(11)
+	if k, v := c.PopOldest(); k != 8 || v != 8 {
+		t.Errorf("expect 8,8;got %v,%v", k, v)
+	}
+	if c.Len() != 1 || c.tests != 0 {
+		t.Errorf("expect 1 item without test items,got %d items %d test items", c.Len(), c.tests)
+	}
+}
@@ -0,0 +1,223 @@
+// Package counter implement a thread safe counter for tallying hashable objects, inspired by python Counter
+// https://docs.python.org/3/library/collections.html#collections.Counter
+package counter
+
+import (
+	"iter"
+	"sort"
+	"sync"
+)
+
+// Counter is a multiset maps keys to their counts, counts may be zero or negative
+type Counter[K comparable] struct {
+	lock   sync.RWMutex
+	counts map[K]*entry
+	// seq is the order of the next key first counted
+	seq uint64
+}
+
+// entry holds the count of a key and the order it was first counted
+type entry struct {
+	count int
+	seq   uint64
+}
+
+// Count is a key with its count
+type Count[K comparable] struct {
+	Key   K
+	Count int
+}
+
+// New return a counter counts each of keys once
+func New[K comparable](keys ...K) *Counter[K] {
+	c := &Counter[K]{
+		counts: make(map[K]*entry),
+	}
+	for _, k := range keys {
+		c.add(k, 1)
+	}
+	return c
+}
+
+// Add delta to the count of key, return the new count
+func (c *Counter[K]) Add(key K, delta int) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.add(key, delta)
+}
+
+// Subtract delta from the count of key, return the new count
+func (c *Counter[K]) Subtract(key K, delta int) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.add(key, -delta)
+}
+
+// Get return the count of key, zero if it isn't counted
+func (c *Counter[K]) Get(key K) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if e, ok := c.counts[key]; ok {
+		return e.count
+	}
+	return 0
+}
+
+// Delete the key from counter, return if it has existed before
+func (c *Counter[K]) Delete(key K) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.counts[key]
+	delete(c.counts, key)
+	return ok
+}
+
+// return the number of keys in counter
+func (c *Counter[K]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.counts)
+}
+
+// Total return the sum of counts
+func (c *Counter[K]) Total() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var total int
+	for _, e := range c.counts {
+		total += e.count
+	}
+	return total
+}
+
+// MostCommon return the n keys of the highest counts from the most common to the least,
+// keys of equal counts are ordered by when they were first counted, all keys if n isn't positive
+func (c *Counter[K]) MostCommon(n int) []Count[K] {
+	counts := c.snapshot()
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	if n > 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// Elements iterate over each key repeated as many times as its count in the order they were first counted,
+// keys of non-positive counts are skipped, the counts are taken when the iteration starts so it's safe to
+// modify the counter in the loop
+func (c *Counter[K]) Elements() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, e := range c.snapshot() {
+			for i := 0; i < e.Count; i++ {
+				if !yield(e.Key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All iterate over the keys and counts in the order they were first counted, the counts are taken when the
+// iteration starts so it's safe to modify the counter in the loop
+func (c *Counter[K]) All() iter.Seq2[K, int] {
+	return func(yield func(K, int) bool) {
+		for _, e := range c.snapshot() {
+			if !yield(e.Key, e.Count) {
+				return
+			}
+		}
+	}
+}
+
+// Update add the counts of other to counter
+func (c *Counter[K]) Update(other *Counter[K]) {
+	counts := other.snapshot()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, e := range counts {
+		c.add(e.Key, e.Count)
+	}
+}
+
+// Sum return a new counter of the counts added, only positive counts are kept
+func (c *Counter[K]) Sum(other *Counter[K]) *Counter[K] {
+	return combine(c, other, func(a, b int) int { return a + b })
+}
+
+// Difference return a new counter of the counts of other subtracted, only positive counts are kept
+func (c *Counter[K]) Difference(other *Counter[K]) *Counter[K] {
+	return combine(c, other, func(a, b int) int { return a - b })
+}
+
+// Union return a new counter of the maximum of the counts, only positive counts are kept
+func (c *Counter[K]) Union(other *Counter[K]) *Counter[K] {
+	return combine(c, other, func(a, b int) int { return max(a, b) })
+}
+
+// Intersection return a new counter of the minimum of the counts, only positive counts are kept
+func (c *Counter[K]) Intersection(other *Counter[K]) *Counter[K] {
+	return combine(c, other, func(a, b int) int { return min(a, b) })
+}
+
+// add delta to the count of key, the lock must be held
+func (c *Counter[K]) add(key K, delta int) int {
+	e, ok := c.counts[key]
+	if !ok {
+		e = &entry{seq: c.seq}
+		c.seq++
+		c.counts[key] = e
+	}
+	e.count += delta
+	return e.count
+}
+
+// return the counts in the order the keys were first counted
+func (c *Counter[K]) snapshot() []Count[K] {
+	c.lock.RLock()
+	keys := make([]K, 0, len(c.counts))
+	entries := make([]entry, 0, len(c.counts))
+	for k, e := range c.counts {
+		keys = append(keys, k)
+		entries = append(entries, *e)
+	}
+	c.lock.RUnlock()
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return entries[order[i]].seq < entries[order[j]].seq
+	})
+	counts := make([]Count[K], len(keys))
+	for i, j := range order {
+		counts[i] = Count[K]{Key: keys[j], Count: entries[j].count}
+	}
+	return counts
+}
+
+// return a new counter of fn applied on the counts of each key in a or b, the keys of a come first
+func combine[K comparable](a, b *Counter[K], fn func(a, b int) int) *Counter[K] {
+	left, right := a.snapshot(), b.snapshot()
+	counts := make(map[K]int, len(right))
+	for _, e := range right {
+		counts[e.Key] = e.Count
+	}
+	c := New[K]()
+	for _, e := range left {
+		if n := fn(e.Count, counts[e.Key]); n > 0 {
+			c.add(e.Key, n)
+		}
+		delete(counts, e.Key)
+	}
+	for _, e := range right {
+		if _, ok := counts[e.Key]; !ok {
+			continue
+		}
+		if n := fn(0, e.Count); n > 0 {
+			c.add(e.Key, n)
+		}
+	}
+	return c
+}
@@ -0,0 +1,121 @@
+package counter
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCounter_Add(t *testing.T) {
+	c := New("a", "b", "a")
+	if v := c.Add("a", 2); v != 4 {
+		t.Errorf("expect 4,got %d", v)
+	}
+	if v := c.Subtract("b", 3); v != -2 {
+		t.Errorf("expect -2,got %d", v)
+	}
+	if v := c.Get("c"); v != 0 {
+		t.Errorf("expect 0,got %d", v)
+	}
+	if v := c.Total(); v != 2 {
+		t.Errorf("expect 2,got %d", v)
+	}
+	if l := c.Len(); l != 2 {
+		t.Errorf("expect 2,got %d", l)
+	}
+	if ok := c.Delete("b"); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := c.Delete("b"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	c := New[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Add(j%10, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := c.Get(3); v != 800 {
+		t.Errorf("expect 800,got %d", v)
+	}
+}
+
+func TestCounter_MostCommon(t *testing.T) {
+	c := New([]rune("abracadabra")...)
+	expect := []Count[rune]{{'a', 5}, {'b', 2}, {'r', 2}}
+	if v := c.MostCommon(3); !cmp.Equal(v, expect) {
+		t.Errorf("expect %v,got %v", expect, v)
+	}
+	if v := c.MostCommon(0); len(v) != 5 || v[4] != (Count[rune]{'d', 1}) {
+		t.Errorf("expect 5 counts end with d,got %v", v)
+	}
+}
+
+func TestCounter_Elements(t *testing.T) {
+	c := New("b", "a", "b")
+	c.Add("c", -1)
+	if v := slices.Collect(c.Elements()); !cmp.Equal(v, []string{"b", "b", "a"}) {
+		t.Errorf("expect [b b a],got %v", v)
+	}
+	for k := range c.Elements() {
+		// modify the counter in the loop is safe
+		c.Add(k, 1)
+		break
+	}
+	if v := c.Get("b"); v != 3 {
+		t.Errorf("expect 3,got %d", v)
+	}
+}
+
+func TestCounter_Update(t *testing.T) {
+	c := New("a", "b")
+	c.Update(New("b", "c"))
+	c.Update(c)
+	if v := c.MostCommon(0); !cmp.Equal(v, []Count[string]{{"b", 4}, {"a", 2}, {"c", 2}}) {
+		t.Errorf("expect [{b 4} {a 2} {c 2}],got %v", v)
+	}
+}
+
+func TestCounter_Arithmetic(t *testing.T) {
+	a := New("x", "x", "x", "y")
+	a.Add("z", -1)
+	b := New("x", "y", "y", "w")
+	tests := []struct {
+		name   string
+		result *Counter[string]
+		expect []Count[string]
+	}{
+		{"Sum", a.Sum(b), []Count[string]{{"x", 4}, {"y", 3}, {"w", 1}}},
+		{"Difference", a.Difference(b), []Count[string]{{"x", 2}}},
+		{"Union", a.Union(b), []Count[string]{{"x", 3}, {"y", 2}, {"w", 1}}},
+		{"Intersection", a.Intersection(b), []Count[string]{{"x", 1}, {"y", 1}}},
+	}
+	for _, tt := range tests {
+		if v := tt.result.MostCommon(0); !cmp.Equal(v, tt.expect) {
+			t.Errorf("%s: expect %v,got %v", tt.name, tt.expect, v)
+		}
+	}
+}
+
+func TestCounter_All(t *testing.T) {
+	c := New("b", "a", "b")
+	var counts []Count[string]
+	for k, n := range c.All() {
+		counts = append(counts, Count[string]{k, n})
+		c.Delete(k)
+	}
+	if !cmp.Equal(counts, []Count[string]{{"b", 2}, {"a", 1}}) || c.Len() != 0 {
+		t.Errorf("expect [{b 2} {a 1}],got %v", counts)
+	}
+}
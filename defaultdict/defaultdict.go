@@ -0,0 +1,138 @@
+// Package defaultdict implement a thread safe map creates the value of a missing key by a factory,
+// inspired by python defaultdict https://docs.python.org/3/library/collections.html#collections.defaultdict
+package defaultdict
+
+import (
+	"iter"
+	"sync"
+)
+
+// DefaultDict is a map whose Get never misses, the value of a key is created by factory on its first access
+type DefaultDict[K comparable, V any] struct {
+	lock    sync.RWMutex
+	items   map[K]V
+	factory func() V
+}
+
+// New return an empty DefaultDict creates missing values by factory, e.g.
+//
+//	groups := defaultdict.New[string](func() []int { return nil })
+//	groups.Update("even", func(v []int) []int { return append(v, 2) })
+func New[K comparable, V any](factory func() V) *DefaultDict[K, V] {
+	return &DefaultDict[K, V]{
+		items:   make(map[K]V),
+		factory: factory,
+	}
+}
+
+// Get return the value of key, it's created by factory and stored atomically if key doesn't exist,
+// a value of reference type is shared with the dict so mutate it by Update rather than in place
+func (d *DefaultDict[K, V]) Get(key K) V {
+	d.lock.RLock()
+	v, ok := d.items[key]
+	d.lock.RUnlock()
+	if ok {
+		return v
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.get(key)
+}
+
+// Lookup return the value of key and whether it exists without creating it
+func (d *DefaultDict[K, V]) Lookup(key K) (value V, ok bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	value, ok = d.items[key]
+	return value, ok
+}
+
+// Update replace the value of key by fn applied on it under the lock, the value is created by factory first
+// if key doesn't exist, return the new value
+func (d *DefaultDict[K, V]) Update(key K, fn func(value V) V) V {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	v := fn(d.get(key))
+	d.items[key] = v
+	return v
+}
+
+// Set the value of key
+func (d *DefaultDict[K, V]) Set(key K, value V) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.items[key] = value
+}
+
+// Contains check if key exists without creating it
+func (d *DefaultDict[K, V]) Contains(key K) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	_, ok := d.items[key]
+	return ok
+}
+
+// Delete the key, return if it has existed before
+func (d *DefaultDict[K, V]) Delete(key K) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, ok := d.items[key]
+	delete(d.items, key)
+	return ok
+}
+
+// return the number of keys
+func (d *DefaultDict[K, V]) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.items)
+}
+
+// Keys return all keys in no particular order
+func (d *DefaultDict[K, V]) Keys() []K {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	keys := make([]K, 0, len(d.items))
+	for k := range d.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// All iterate over the keys and values in no particular order, they're taken when the iteration starts
+// so it's safe to modify the dict in the loop
+func (d *DefaultDict[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.lock.RLock()
+		keys := make([]K, 0, len(d.items))
+		values := make([]V, 0, len(d.items))
+		for k, v := range d.items {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		d.lock.RUnlock()
+		for i, k := range keys {
+			if !yield(k, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Purge use to clear all keys
+func (d *DefaultDict[K, V]) Purge() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	clear(d.items)
+}
+
+// return the value of key, create it if key doesn't exist, the write lock must be held
+func (d *DefaultDict[K, V]) get(key K) V {
+	v, ok := d.items[key]
+	if !ok {
+		v = d.factory()
+		d.items[key] = v
+	}
+	return v
+}
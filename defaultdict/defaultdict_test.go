@@ -0,0 +1,91 @@
+package defaultdict
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDefaultDict_Get(t *testing.T) {
+	var created int
+	d := New[string](func() int {
+		created++
+		return 10
+	})
+	if v, ok := d.Lookup("a"); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if v := d.Get("a"); v != 10 {
+		t.Errorf("expect 10,got %d", v)
+	}
+	d.Get("a")
+	if created != 1 || !d.Contains("a") {
+		t.Errorf("expect created once,got %d", created)
+	}
+	d.Set("b", 2)
+	if v := d.Get("b"); v != 2 || created != 1 {
+		t.Errorf("expect 2 without creating,got %d created %d", v, created)
+	}
+}
+
+func TestDefaultDict_Update(t *testing.T) {
+	d := New[string](func() []int { return nil })
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := "odd"
+			if i%2 == 0 {
+				key = "even"
+			}
+			d.Update(key, func(v []int) []int {
+				return append(v, i)
+			})
+		}(i)
+	}
+	wg.Wait()
+	even := d.Get("even")
+	sort.Ints(even)
+	if len(even) != 50 || even[0] != 0 || even[49] != 98 || len(d.Get("odd")) != 50 {
+		t.Errorf("expect 50 even and 50 odd,got %v %v", even, d.Get("odd"))
+	}
+}
+
+func TestDefaultDict_Delete(t *testing.T) {
+	d := New[int](func() string { return "" })
+	d.Get(1)
+	d.Get(2)
+	keys := d.Keys()
+	sort.Ints(keys)
+	if !cmp.Equal(keys, []int{1, 2}) || d.Len() != 2 {
+		t.Errorf("expect [1 2],got %v", keys)
+	}
+	if ok := d.Delete(1); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := d.Delete(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	d.Purge()
+	if l := d.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestDefaultDict_All(t *testing.T) {
+	d := New[int](func() int { return 0 })
+	for i := 0; i < 4; i++ {
+		d.Set(i, i*10)
+	}
+	seen := make(map[int]int)
+	for k, v := range d.All() {
+		seen[k] = v
+		d.Delete(k)
+	}
+	if len(seen) != 4 || seen[3] != 30 || d.Len() != 0 {
+		t.Errorf("expect 4 pairs,got %v", seen)
+	}
+}
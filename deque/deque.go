@@ -1,204 +1,391 @@
 // Package deque implement a fixed size thread safe queue as a generalization of both queue and stack
+// items are kept in a growable circular buffer, so pushes and pops don't allocate and any item can be accessed by index
 package deque
 
 import (
-	"container/list"
+	"iter"
 	"sync"
 )
 
 const (
 	Default_Deque_Size = 1024
+	// the initial size of buffer, it doubles until reaching the capacity of deque
+	minBufferSize = 16
 )
 
 // Deque implement a queue as a generalization of both queue and stack
-type Deque struct {
+type Deque[T any] struct {
 	capacity int
 	lock     sync.RWMutex
-	data     *list.List
-	counter  map[interface{}]int
+	// buf holds the items from buf[head] wrapping around to buf[(head+length-1)%len(buf)]
+	buf    []T
+	head   int
+	length int
+	equal  func(a, b T) bool
 }
 
-// a fixed size deque
-func NewDeque(size int) *Deque {
+// a fixed size deque holds arbitrary items
+func NewDeque(size int) *Deque[interface{}] {
+	return New[interface{}](size)
+}
+
+// New return a fixed size deque holds items of type T
+func New[T comparable](size int) *Deque[T] {
+	return NewFunc(size, func(a, b T) bool {
+		return a == b
+	})
+}
+
+// NewFunc return a fixed size deque holds items of type T, which compared by equal in Remove, Count and Contains
+func NewFunc[T any](size int, equal func(a, b T) bool) *Deque[T] {
 	if size <= 0 {
 		size = Default_Deque_Size
 	}
-	return &Deque{
+	return &Deque[T]{
 		capacity: size,
-		data:     list.New(),
-		counter:  make(map[interface{}]int),
+		equal:    equal,
 	}
 }
 
 // push a new item into deque from left
-func (q *Deque) PushLeft(item interface{}) (evicted bool) {
+func (q *Deque[T]) PushLeft(item T) (evicted bool) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	q.data.PushFront(item)
-	q.counter[item] += 1
-	if q.data.Len() > q.capacity {
-		n := q.data.Back()
-		q.counter[n.Value] -= 1
-		q.data.Remove(n)
-		return true
+	if q.length == q.capacity {
+		q.popRight()
+		evicted = true
 	}
-	return false
+	q.grow()
+	q.head = q.index(-1)
+	q.buf[q.head] = item
+	q.length++
+	return evicted
 }
 
 // push a new item into deque from right
-func (q *Deque) PushRight(item interface{}) (evicted bool) {
+func (q *Deque[T]) PushRight(item T) (evicted bool) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	q.data.PushBack(item)
-	q.counter[item] += 1
-	if q.data.Len() > q.capacity {
-		n := q.data.Front()
-		q.counter[n.Value] -= 1
-		q.data.Remove(n)
-		return true
+	if q.length == q.capacity {
+		q.popLeft()
+		evicted = true
 	}
-	return false
+	q.grow()
+	q.buf[q.index(q.length)] = item
+	q.length++
+	return evicted
 }
 
 // pop a item from left
-func (q *Deque) PopLeft() interface{} {
+func (q *Deque[T]) PopLeft() (item T) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	if q.data.Len() == 0 {
-		return nil
+	if q.length == 0 {
+		return item
 	}
-	n := q.data.Front()
-	q.counter[n.Value] -= 1
-	return q.data.Remove(q.data.Front())
+	return q.popLeft()
 }
 
 // get a item from left
-func (q *Deque) GetLeft() interface{} {
+func (q *Deque[T]) GetLeft() (item T) {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
-	if q.data.Len() == 0 {
-		return nil
+	if q.length == 0 {
+		return item
 	}
-	return q.data.Front().Value
+	return q.buf[q.head]
 }
 
 // pop a item from right
-func (q *Deque) PopRight() interface{} {
+func (q *Deque[T]) PopRight() (item T) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	if q.data.Len() == 0 {
-		return nil
+	if q.length == 0 {
+		return item
 	}
-	n := q.data.Back()
-	q.counter[n.Value] -= 1
-	return q.data.Remove(n)
+	return q.popRight()
 }
 
 // get a item from right
-func (q *Deque) GetRight() interface{} {
+func (q *Deque[T]) GetRight() (item T) {
 	q.lock.RLock()
-	defer q.lock.RLock()
-	if q.data.Len() == 0 {
-		return nil
+	defer q.lock.RUnlock()
+	if q.length == 0 {
+		return item
 	}
-	return q.data.Back().Value
+	return q.buf[q.index(q.length-1)]
 }
 
-// remove the first occurrence of value
-func (q *Deque) Remove(value interface{}) {
+// At return the i-th item from left, a negative i counts from right as -1 is the rightmost item
+func (q *Deque[T]) At(i int) (item T, ok bool) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if i, ok = q.position(i); !ok {
+		return item, false
+	}
+	return q.buf[q.index(i)], true
+}
+
+// Set replace the i-th item from left, a negative i counts from right, return false if i is out of range
+func (q *Deque[T]) Set(i int, item T) bool {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	if v, ok := q.counter[value]; !ok || v <= 0 {
-		return
+	i, ok := q.position(i)
+	if ok {
+		q.buf[q.index(i)] = item
+	}
+	return ok
+}
+
+// Insert item so it becomes the i-th item from left, a negative i counts from right,
+// i is clamped to the deque like python does, return false if the deque is full
+func (q *Deque[T]) Insert(i int, item T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.length == q.capacity {
+		return false
+	}
+	if i < 0 {
+		i += q.length
+	}
+	i = min(max(i, 0), q.length)
+	q.grow()
+	// shift the shorter side by one to make room
+	if i < q.length/2 {
+		q.head = q.index(-1)
+		for j := 0; j < i; j++ {
+			q.buf[q.index(j)] = q.buf[q.index(j+1)]
+		}
+	} else {
+		for j := q.length; j > i; j-- {
+			q.buf[q.index(j)] = q.buf[q.index(j-1)]
+		}
 	}
-	for i := q.data.Front(); i != nil; i = i.Next() {
-		if i.Value == value {
-			q.data.Remove(i)
-			q.counter[value] -= 1
+	q.buf[q.index(i)] = item
+	q.length++
+	return true
+}
+
+// remove the first occurrence of value
+func (q *Deque[T]) Remove(value T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for i := 0; i < q.length; i++ {
+		if q.equal(q.buf[q.index(i)], value) {
+			q.removeAt(i)
+			return
 		}
 	}
 }
 
 // reverse the deque
-func (q *Deque) Reverse() {
+func (q *Deque[T]) Reverse() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	if q.data.Len() == 0 {
-		return
-	}
-	newList := list.New()
-	for i := q.data.Back(); i != nil; i = i.Prev() {
-		newList.PushBack(i.Value)
+	for i, j := 0, q.length-1; i < j; i, j = i+1, j-1 {
+		x, y := q.index(i), q.index(j)
+		q.buf[x], q.buf[y] = q.buf[y], q.buf[x]
 	}
-	q.data = newList
 }
 
 // return the data length of deque
-func (q *Deque) Len() int {
+func (q *Deque[T]) Len() int {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
-	return q.data.Len()
+	return q.length
 }
 
 // return the max capacity of deque
-func (q *Deque) Cap() int {
+func (q *Deque[T]) Cap() int {
 	return q.capacity
 }
 
 // clear the deque
-func (q *Deque) Purge() {
+func (q *Deque[T]) Purge() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	q.counter = make(map[interface{}]int)
-	q.data = list.New()
+	clear(q.buf)
+	q.head = 0
+	q.length = 0
 }
 
-// rotate the deque n steps to the right
-func (q *Deque) Rotate(step int) {
+// rotate the deque step steps to the left, so the item at step becomes the leftmost one,
+// a negative step rotates to the right, it takes O(1) if the buffer is full, otherwise the min(step, Len-step)
+// items of the shorter side are copied across the free room of the buffer to the other end
+func (q *Deque[T]) Rotate(step int) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	if step == 0 {
+	if q.length == 0 {
 		return
 	}
-	if step > 0 {
-		n := step % q.data.Len()
-		for i := 0; i < n; i++ {
-			q.data.PushBack(q.data.Remove(q.data.Front()))
-		}
+	n := step % q.length
+	if n < 0 {
+		n += q.length
 	}
-	if step < 0 {
-		n := (0 - step) % q.data.Len()
-		for i := 0; i < n; i++ {
-			q.data.PushFront(q.data.Remove(q.data.Back()))
+	if n == 0 {
+		return
+	}
+	// a full buffer only needs to move its head
+	if q.length == len(q.buf) {
+		q.head = q.index(n)
+		return
+	}
+	// every copy moves at most as many items as the free room, so it never overwrites the items to be moved
+	room := len(q.buf) - q.length
+	if n <= q.length/2 {
+		for n > 0 {
+			k := min(n, room)
+			q.move(q.index(q.length), q.head, k)
+			q.head = q.index(k)
+			n -= k
 		}
+		return
+	}
+	for n = q.length - n; n > 0; {
+		k := min(n, room)
+		q.move(q.index(-k), q.index(q.length-k), k)
+		q.head = q.index(-k)
+		n -= k
 	}
 }
 
 // count the given value in the deque
-func (q *Deque) Count(value interface{}) int {
+func (q *Deque[T]) Count(value T) int {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
-	v := q.counter[value]
-	return v
+	var count int
+	for i := 0; i < q.length; i++ {
+		if q.equal(q.buf[q.index(i)], value) {
+			count++
+		}
+	}
+	return count
 }
 
 // check if the deque contain the given value
-func (q *Deque) Contains(value interface{}) bool {
-	q.lock.Lock()
-	defer q.lock.Unlock()
-	v, ok := q.counter[value]
-	return ok && v > 0
+func (q *Deque[T]) Contains(value T) bool {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	for i := 0; i < q.length; i++ {
+		if q.equal(q.buf[q.index(i)], value) {
+			return true
+		}
+	}
+	return false
 }
 
 // get all values from deque
-func (q *Deque) GetAll() []interface{} {
-	q.lock.Lock()
-	defer q.lock.Unlock()
-	keys := make([]interface{}, q.data.Len())
-	head := q.data.Front()
-	for i := 0; i < q.data.Len(); i++ {
-		keys[i] = head.Value
-		head = head.Next()
+func (q *Deque[T]) GetAll() []T {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	keys := make([]T, q.length)
+	for i := range keys {
+		keys[i] = q.buf[q.index(i)]
 	}
 	return keys
 }
+
+// return the position in buf of the i-th item from left, i may be -1 or up to length
+func (q *Deque[T]) index(i int) int {
+	return (q.head + i + len(q.buf)) % len(q.buf)
+}
+
+// return the i-th position from left, a negative i counts from right, and if it's in range
+func (q *Deque[T]) position(i int) (int, bool) {
+	if i < 0 {
+		i += q.length
+	}
+	return i, i >= 0 && i < q.length
+}
+
+// grow the buffer so there is room for one more item
+func (q *Deque[T]) grow() {
+	if q.length < len(q.buf) {
+		return
+	}
+	buf := make([]T, min(max(2*len(q.buf), minBufferSize), q.capacity))
+	for i := 0; i < q.length; i++ {
+		buf[i] = q.buf[q.index(i)]
+	}
+	q.buf = buf
+	q.head = 0
+}
+
+// move count items from position src to position dst of buf wrapping around its end, the positions left are
+// cleared, the two ranges must not overlap
+func (q *Deque[T]) move(dst, src, count int) {
+	for count > 0 {
+		k := min(count, len(q.buf)-src, len(q.buf)-dst)
+		copy(q.buf[dst:dst+k], q.buf[src:src+k])
+		clear(q.buf[src : src+k])
+		dst, src, count = (dst+k)%len(q.buf), (src+k)%len(q.buf), count-k
+	}
+}
+
+// pop the leftmost item, the deque must not be empty
+func (q *Deque[T]) popLeft() (item T) {
+	item = q.buf[q.head]
+	q.buf[q.head] = *new(T)
+	q.head = q.index(1)
+	q.length--
+	return item
+}
+
+// pop the rightmost item, the deque must not be empty
+func (q *Deque[T]) popRight() (item T) {
+	last := q.index(q.length - 1)
+	item = q.buf[last]
+	q.buf[last] = *new(T)
+	q.length--
+	return item
+}
+
+// remove the i-th item from left by shifting the shorter side
+func (q *Deque[T]) removeAt(i int) {
+	if i < q.length/2 {
+		for j := i; j > 0; j-- {
+			q.buf[q.index(j)] = q.buf[q.index(j-1)]
+		}
+		q.popLeft()
+	} else {
+		for j := i; j < q.length-1; j++ {
+			q.buf[q.index(j)] = q.buf[q.index(j+1)]
+		}
+		q.popRight()
+	}
+}
+
+// All iterate over the positions and items from left to right, the items are taken when the iteration starts
+// so it's safe to modify the deque in the loop
+func (q *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, item := range q.GetAll() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the positions and items in the reverse order of All
+func (q *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		items := q.GetAll()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(i, items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterate over the items in the order of All
+func (q *Deque[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range q.GetAll() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
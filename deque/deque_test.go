@@ -1,8 +1,11 @@
 package deque
 
 import (
-	"github.com/google/go-cmp/cmp"
+	"math/rand"
+	"slices"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestDeque_Cap(t *testing.T) {
@@ -209,6 +212,189 @@ func TestDeque_Rotate(t *testing.T) {
 	}
 }
 
+func TestDeque_Typed(t *testing.T) {
+	q := New[string](2)
+	if v := q.PopLeft(); v != "" {
+		t.Errorf("expect empty string,got %q", v)
+	}
+	q.PushRight("a")
+	q.PushRight("b")
+	q.PushRight("a")
+	if !cmp.Equal(q.GetAll(), []string{"b", "a"}) {
+		t.Errorf("expect [b a],got %v", q.GetAll())
+	}
+}
+
+func TestDeque_NewFunc(t *testing.T) {
+	q := NewFunc(32, func(a, b []int) bool {
+		return cmp.Equal(a, b)
+	})
+	q.PushRight([]int{1, 2})
+	q.PushRight([]int{3})
+	q.PushRight([]int{1, 2})
+	if v := q.Count([]int{1, 2}); v != 2 {
+		t.Errorf("expect 2,got %d", v)
+	}
+	q.Remove([]int{1, 2})
+	if v := q.Count([]int{1, 2}); v != 1 {
+		t.Errorf("expect 1,got %d", v)
+	}
+	if ok := q.Contains([]int{4}); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+}
+
+func TestDeque_RotateEmpty(t *testing.T) {
+	q := New[int](8)
+	q.Rotate(3)
+	if l := q.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestDeque_At(t *testing.T) {
+	q := New[int](8)
+	if v, ok := q.At(0); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	for i := 1; i <= 4; i++ {
+		q.PushRight(i)
+	}
+	for i, expect := range map[int]int{0: 1, 3: 4, -1: 4, -4: 1} {
+		if v, ok := q.At(i); !ok || v != expect {
+			t.Errorf("expect %d with true at %d,got %v with %v", expect, i, v, ok)
+		}
+	}
+	for _, i := range []int{4, -5} {
+		if v, ok := q.At(i); ok {
+			t.Errorf("expect false at %d,got %v with %v", i, v, ok)
+		}
+	}
+}
+
+func TestDeque_Set(t *testing.T) {
+	q := New[int](8)
+	if ok := q.Set(0, 1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	q.PushRight(1)
+	q.PushRight(2)
+	q.Set(0, 10)
+	q.Set(-1, 20)
+	if !cmp.Equal(q.GetAll(), []int{10, 20}) {
+		t.Errorf("expect [10 20],got %v", q.GetAll())
+	}
+}
+
+func TestDeque_Insert(t *testing.T) {
+	q := New[int](6)
+	q.Insert(0, 3)
+	q.Insert(0, 1)
+	q.Insert(1, 2)
+	q.Insert(10, 5)
+	q.Insert(-1, 4)
+	q.Insert(-10, 0)
+	if !cmp.Equal(q.GetAll(), []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("expect [0 1 2 3 4 5],got %v", q.GetAll())
+	}
+	if ok := q.Insert(0, 6); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+}
+
+// TestDeque_Model compare the deque against a slice doing the same random operations, so every wrap around
+// of the circular buffer is exercised
+func TestDeque_Model(t *testing.T) {
+	const size = 37
+	r := rand.New(rand.NewSource(1))
+	q := New[int](size)
+	model := []int{}
+	for i := 0; i < 100000; i++ {
+		switch op := r.Intn(9); op {
+		case 0:
+			q.PushLeft(i)
+			model = append([]int{i}, model...)
+			if len(model) > size {
+				model = model[:size]
+			}
+		case 1:
+			q.PushRight(i)
+			model = append(model, i)
+			if len(model) > size {
+				model = model[1:]
+			}
+		case 2:
+			v := q.PopLeft()
+			if len(model) > 0 {
+				if v != model[0] {
+					t.Fatalf("expect %d,got %d", model[0], v)
+				}
+				model = model[1:]
+			}
+		case 3:
+			v := q.PopRight()
+			if len(model) > 0 {
+				if v != model[len(model)-1] {
+					t.Fatalf("expect %d,got %d", model[len(model)-1], v)
+				}
+				model = model[:len(model)-1]
+			}
+		case 4:
+			step := r.Intn(2*size) - size
+			q.Rotate(step)
+			if n := len(model); n > 0 {
+				k := ((step % n) + n) % n
+				model = append(append([]int{}, model[k:]...), model[:k]...)
+			}
+		case 5:
+			j := r.Intn(size+2) - 1
+			if q.Insert(j, i) {
+				if j < 0 {
+					j = max(j+len(model), 0)
+				}
+				j = min(j, len(model))
+				model = append(model[:j], append([]int{i}, model[j:]...)...)
+			}
+		case 6:
+			if len(model) > 0 {
+				j := r.Intn(len(model))
+				q.Remove(model[j])
+				model = append(model[:j], model[j+1:]...)
+			}
+		case 7:
+			q.Reverse()
+			for a, b := 0, len(model)-1; a < b; a, b = a+1, b-1 {
+				model[a], model[b] = model[b], model[a]
+			}
+		case 8:
+			if len(model) > 0 {
+				j := r.Intn(len(model))
+				q.Set(j, i)
+				model[j] = i
+			}
+		}
+		if got := q.GetAll(); !cmp.Equal(got, model) {
+			t.Fatalf("expect %v,got %v", model, got)
+		}
+	}
+}
+
+func TestDeque_NoAlloc(t *testing.T) {
+	q := New[int](64)
+	for i := 0; i < 64; i++ {
+		q.PushRight(i)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		q.PushRight(1)
+		q.PushLeft(1)
+		q.PopLeft()
+		q.Rotate(3)
+	})
+	if allocs != 0 {
+		t.Errorf("expect 0 allocations,got %v", allocs)
+	}
+}
+
 func BenchmarkDeque_Push(b *testing.B) {
 	b.StopTimer()
 	q := NewDeque(8096)
@@ -235,3 +421,65 @@ func BenchmarkDeque_Pop(b *testing.B) {
 		q.PopRight()
 	}
 }
+
+func TestDeque_All(t *testing.T) {
+	q := New[int](8)
+	for i := 1; i <= 3; i++ {
+		q.PushRight(i)
+	}
+	var positions []int
+	var items []int
+	for i, v := range q.All() {
+		positions = append(positions, i)
+		items = append(items, v)
+		// modify the deque in the loop is safe
+		q.PopLeft()
+	}
+	if !cmp.Equal(positions, []int{0, 1, 2}) || !cmp.Equal(items, []int{1, 2, 3}) {
+		t.Errorf("expect [0 1 2] [1 2 3],got %v %v", positions, items)
+	}
+	for i := 1; i <= 3; i++ {
+		q.PushRight(i)
+	}
+	var backward []int
+	for i, v := range q.Backward() {
+		if i == 0 {
+			break
+		}
+		backward = append(backward, v)
+	}
+	if values := slices.Collect(q.Values()); !cmp.Equal(values, []int{1, 2, 3}) || !cmp.Equal(backward, []int{3, 2}) {
+		t.Errorf("expect [1 2 3] [3 2],got %v %v", values, backward)
+	}
+}
+
+func TestDeque_RotatePartial(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 200 {
+		q := New[int](64)
+		var expect []int
+		// push from both sides so the items wrap around the end of the buffer
+		for i := range r.Intn(40) + 1 {
+			if r.Intn(2) == 0 {
+				q.PushLeft(i)
+				expect = append([]int{i}, expect...)
+			} else {
+				q.PushRight(i)
+				expect = append(expect, i)
+			}
+		}
+		step := r.Intn(4*len(expect)) - 2*len(expect)
+		q.Rotate(step)
+		n := (step%len(expect) + len(expect)) % len(expect)
+		expect = append(expect[n:], expect[:n]...)
+		if diff := cmp.Diff(expect, q.GetAll()); diff != "" {
+			t.Fatalf("rotate %d mismatch (-want +got):\n%s", step, diff)
+		}
+		// the free room of the buffer is left cleared
+		for i := q.length; i < len(q.buf); i++ {
+			if v := q.buf[q.index(i)]; v != 0 {
+				t.Fatalf("expect free room cleared,got %d at %d", v, i)
+			}
+		}
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/FelixSeptem/collections
 
+go 1.24
+
 require github.com/google/go-cmp v0.2.0
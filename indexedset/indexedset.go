@@ -0,0 +1,188 @@
+// Package indexedset implement a thread safe set keeps the insertion order and can be indexed by position,
+// inspired by boltons IndexedSet https://boltons.readthedocs.io/en/latest/setutils.html
+package indexedset
+
+import (
+	"iter"
+	"sync"
+)
+
+// IndexedSet is a set of unique values in the order they were added, the zero value isn't ready to use
+type IndexedSet[T comparable] struct {
+	lock   sync.RWMutex
+	values []T
+	// index maps a value to its position in values
+	index map[T]int
+}
+
+// New return a set holds values, the duplicates are dropped
+func New[T comparable](values ...T) *IndexedSet[T] {
+	s := &IndexedSet[T]{
+		index: make(map[T]int, len(values)),
+	}
+	for _, v := range values {
+		s.add(v)
+	}
+	return s
+}
+
+// Add value at the end of set, return false if it's in the set already
+func (s *IndexedSet[T]) Add(value T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.add(value)
+}
+
+// Remove value from set, the values after it are shifted so it takes linear time, return if it has existed before
+func (s *IndexedSet[T]) Remove(value T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	i, ok := s.index[value]
+	if ok {
+		s.removeAt(i)
+	}
+	return ok
+}
+
+// Contains check if value is in the set
+func (s *IndexedSet[T]) Contains(value T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.index[value]
+	return ok
+}
+
+// At return the i-th value, a negative i counts from the end as -1 is the last value
+func (s *IndexedSet[T]) At(i int) (value T, ok bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if i, ok = s.position(i); !ok {
+		return value, false
+	}
+	return s.values[i], true
+}
+
+// IndexOf return the position of value, -1 if it isn't in the set
+func (s *IndexedSet[T]) IndexOf(value T) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if i, ok := s.index[value]; ok {
+		return i
+	}
+	return -1
+}
+
+// Pop remove and return the i-th value, a negative i counts from the end, so Pop(-1) takes constant time
+func (s *IndexedSet[T]) Pop(i int) (value T, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if i, ok = s.position(i); !ok {
+		return value, false
+	}
+	value = s.values[i]
+	s.removeAt(i)
+	return value, true
+}
+
+// return the number of values
+func (s *IndexedSet[T]) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.values)
+}
+
+// Values return all values in order
+func (s *IndexedSet[T]) Values() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	values := make([]T, len(s.values))
+	copy(values, s.values)
+	return values
+}
+
+// All iterate over the positions and values in order, the values are taken when the iteration starts
+// so it's safe to modify the set in the loop
+func (s *IndexedSet[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range s.Values() {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// Purge use to clear all values
+func (s *IndexedSet[T]) Purge() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	clear(s.values)
+	s.values = s.values[:0]
+	clear(s.index)
+}
+
+// Union return a new set of the values in s followed by the ones only in other
+func (s *IndexedSet[T]) Union(other *IndexedSet[T]) *IndexedSet[T] {
+	return New(append(s.Values(), other.Values()...)...)
+}
+
+// Intersection return a new set of the values in s which are also in other
+func (s *IndexedSet[T]) Intersection(other *IndexedSet[T]) *IndexedSet[T] {
+	return s.filter(other, true)
+}
+
+// Difference return a new set of the values in s which aren't in other
+func (s *IndexedSet[T]) Difference(other *IndexedSet[T]) *IndexedSet[T] {
+	return s.filter(other, false)
+}
+
+// SymmetricDifference return a new set of the values only in s followed by the ones only in other
+func (s *IndexedSet[T]) SymmetricDifference(other *IndexedSet[T]) *IndexedSet[T] {
+	result := s.filter(other, false)
+	for _, v := range other.filter(s, false).values {
+		result.add(v)
+	}
+	return result
+}
+
+// return a new set of the values in s which are in other or not as in tell
+func (s *IndexedSet[T]) filter(other *IndexedSet[T], in bool) *IndexedSet[T] {
+	values, lookup := s.Values(), New(other.Values()...)
+	result := New[T]()
+	for _, v := range values {
+		if _, ok := lookup.index[v]; ok == in {
+			result.add(v)
+		}
+	}
+	return result
+}
+
+// add value at the end, the lock must be held
+func (s *IndexedSet[T]) add(value T) bool {
+	if _, ok := s.index[value]; ok {
+		return false
+	}
+	s.index[value] = len(s.values)
+	s.values = append(s.values, value)
+	return true
+}
+
+// remove the i-th value and shift the ones after it, the lock must be held
+func (s *IndexedSet[T]) removeAt(i int) {
+	delete(s.index, s.values[i])
+	copy(s.values[i:], s.values[i+1:])
+	var zero T
+	s.values[len(s.values)-1] = zero
+	s.values = s.values[:len(s.values)-1]
+	for j := i; j < len(s.values); j++ {
+		s.index[s.values[j]] = j
+	}
+}
+
+// return the i-th position, a negative i counts from the end, and if it's in range
+func (s *IndexedSet[T]) position(i int) (int, bool) {
+	if i < 0 {
+		i += len(s.values)
+	}
+	return i, i >= 0 && i < len(s.values)
+}
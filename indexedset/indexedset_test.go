@@ -0,0 +1,102 @@
+package indexedset
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndexedSet_Add(t *testing.T) {
+	s := New("b", "a", "b")
+	if added := s.Add("c"); !added {
+		t.Errorf("expect true,got %v", added)
+	}
+	if added := s.Add("a"); added {
+		t.Errorf("expect false,got %v", added)
+	}
+	if !cmp.Equal(s.Values(), []string{"b", "a", "c"}) || s.Len() != 3 {
+		t.Errorf("expect [b a c],got %v", s.Values())
+	}
+	if !s.Contains("a") || s.Contains("x") {
+		t.Errorf("expect a in set without x,got %v", s.Values())
+	}
+}
+
+func TestIndexedSet_At(t *testing.T) {
+	s := New(10, 20, 30)
+	for i, expect := range map[int]int{0: 10, 2: 30, -1: 30, -3: 10} {
+		if v, ok := s.At(i); !ok || v != expect {
+			t.Errorf("expect %d with true at %d,got %v with %v", expect, i, v, ok)
+		}
+	}
+	if v, ok := s.At(3); ok {
+		t.Errorf("expect false,got %v with %v", v, ok)
+	}
+	if i := s.IndexOf(20); i != 1 {
+		t.Errorf("expect 1,got %d", i)
+	}
+	if i := s.IndexOf(40); i != -1 {
+		t.Errorf("expect -1,got %d", i)
+	}
+}
+
+func TestIndexedSet_Pop(t *testing.T) {
+	s := New(1, 2, 3, 4, 5)
+	if v, ok := s.Pop(-1); !ok || v != 5 {
+		t.Errorf("expect 5 with true,got %v with %v", v, ok)
+	}
+	if v, ok := s.Pop(1); !ok || v != 2 {
+		t.Errorf("expect 2 with true,got %v with %v", v, ok)
+	}
+	if ok := s.Remove(1); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := s.Remove(1); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if !cmp.Equal(s.Values(), []int{3, 4}) || s.IndexOf(4) != 1 {
+		t.Errorf("expect [3 4] with 4 at 1,got %v with 4 at %d", s.Values(), s.IndexOf(4))
+	}
+	if v, ok := s.Pop(5); ok {
+		t.Errorf("expect false,got %v with %v", v, ok)
+	}
+	s.Purge()
+	if l := s.Len(); l != 0 || s.Contains(3) {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestIndexedSet_All(t *testing.T) {
+	s := New("a", "b", "c")
+	var values []string
+	for i, v := range s.All() {
+		if i == 2 {
+			break
+		}
+		values = append(values, v)
+		// modify the set in the loop is safe
+		s.Remove(v)
+	}
+	if !cmp.Equal(values, []string{"a", "b"}) || !cmp.Equal(s.Values(), []string{"c"}) {
+		t.Errorf("expect [a b] with [c] left,got %v with %v left", values, s.Values())
+	}
+}
+
+func TestIndexedSet_Algebra(t *testing.T) {
+	a, b := New(5, 1, 3, 7), New(3, 2, 5, 8)
+	tests := []struct {
+		name   string
+		result *IndexedSet[int]
+		expect []int
+	}{
+		{"Union", a.Union(b), []int{5, 1, 3, 7, 2, 8}},
+		{"Intersection", a.Intersection(b), []int{5, 3}},
+		{"Difference", a.Difference(b), []int{1, 7}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 7, 2, 8}},
+	}
+	for _, tt := range tests {
+		if v := tt.result.Values(); !cmp.Equal(v, tt.expect) {
+			t.Errorf("%s: expect %v,got %v", tt.name, tt.expect, v)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package evict buffer the items evicted while a cache holds its lock, so the eviction callback can be invoked after the lock is released
+package evict
+
+import (
+	"sync"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// item is an eviction waiting to be delivered
+type item[K comparable, V any] struct {
+	key    K
+	value  V
+	reason cache.EvictReason
+}
+
+// Notifier collects evictions for the callback, the zero value drops everything
+type Notifier[K comparable, V any] struct {
+	callback cache.EvictCallback[K, V]
+	pending  []item[K, V]
+}
+
+// SetCallback set the callback evictions delivered to
+func (n *Notifier[K, V]) SetCallback(fn cache.EvictCallback[K, V]) {
+	n.callback = fn
+}
+
+// Add record an eviction, shall be called with the cache lock held
+func (n *Notifier[K, V]) Add(key K, value V, reason cache.EvictReason) {
+	if n.callback == nil {
+		return
+	}
+	n.pending = append(n.pending, item[K, V]{key: key, value: value, reason: reason})
+}
+
+// Take return the evictions recorded so far and a function delivers them, shall be called with the cache lock held
+// while the returned function shall be called after the lock is released
+func (n *Notifier[K, V]) Take() func() {
+	if len(n.pending) == 0 {
+		return func() {}
+	}
+	pending, callback := n.pending, n.callback
+	n.pending = nil
+	return func() {
+		for _, e := range pending {
+			callback(e.key, e.value, e.reason)
+		}
+	}
+}
+
+// Unlock release the cache lock l then deliver the evictions recorded while holding it
+func (n *Notifier[K, V]) Unlock(l sync.Locker) {
+	deliver := n.Take()
+	l.Unlock()
+	deliver()
+}
@@ -0,0 +1,54 @@
+package evict
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+func TestNotifier_Take(t *testing.T) {
+	var got []string
+	var n Notifier[string, int]
+	n.Add("dropped", 0, cache.EvictRemoved)
+	n.SetCallback(func(key string, value int, reason cache.EvictReason) {
+		got = append(got, key+":"+reason.String())
+	})
+	n.Add("a", 1, cache.EvictCapacity)
+	n.Add("b", 2, cache.EvictPurged)
+	deliver := n.Take()
+	if len(got) != 0 {
+		t.Errorf("expect nothing delivered before calling,got %v", got)
+	}
+	deliver()
+	if len(got) != 2 || got[0] != "a:capacity" || got[1] != "b:purged" {
+		t.Errorf("expect [a:capacity b:purged],got %v", got)
+	}
+	n.Take()()
+	if len(got) != 2 {
+		t.Errorf("expect 2,got %v", got)
+	}
+}
+
+func TestNotifier_Unlock(t *testing.T) {
+	var (
+		n    Notifier[string, int]
+		lock sync.Mutex
+		got  []string
+	)
+	n.SetCallback(func(key string, value int, reason cache.EvictReason) {
+		// the lock is released before the callback runs
+		if !lock.TryLock() {
+			t.Errorf("expect lock released")
+			return
+		}
+		lock.Unlock()
+		got = append(got, key)
+	})
+	lock.Lock()
+	n.Add("a", 1, cache.EvictCapacity)
+	n.Unlock(&lock)
+	if len(got) != 1 || got[0] != "a" {
+		t.Errorf("expect [a],got %v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package list
+
+import "testing"
+
+func checkList(t *testing.T, l *List[int], values []int) {
+	t.Helper()
+	if l.Len() != len(values) {
+		t.Fatalf("expect len %d,got %d", len(values), l.Len())
+	}
+	i := 0
+	for e := l.Front(); e != nil; e = e.Next() {
+		if e.Value != values[i] {
+			t.Errorf("expect %d at %d,got %d", values[i], i, e.Value)
+		}
+		i++
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		i--
+		if e.Value != values[i] {
+			t.Errorf("expect %d at %d,got %d", values[i], i, e.Value)
+		}
+	}
+}
+
+func TestList_Push(t *testing.T) {
+	l := New[int]()
+	checkList(t, l, []int{})
+	e1 := l.PushBack(1)
+	l.PushFront(0)
+	l.PushBack(2)
+	checkList(t, l, []int{0, 1, 2})
+	l.InsertBefore(3, e1)
+	l.InsertAfter(4, e1)
+	checkList(t, l, []int{0, 3, 1, 4, 2})
+}
+
+func TestList_Move(t *testing.T) {
+	l := New[int]()
+	e1 := l.PushBack(1)
+	e2 := l.PushBack(2)
+	e3 := l.PushBack(3)
+	l.MoveToFront(e3)
+	checkList(t, l, []int{3, 1, 2})
+	l.MoveToBack(e3)
+	checkList(t, l, []int{1, 2, 3})
+	l.MoveBefore(e2, e1)
+	checkList(t, l, []int{2, 1, 3})
+	l.MoveAfter(e2, e3)
+	checkList(t, l, []int{1, 3, 2})
+}
+
+func TestList_Remove(t *testing.T) {
+	l := New[int]()
+	e1 := l.PushBack(1)
+	l.PushBack(2)
+	if v := l.Remove(e1); v != 1 {
+		t.Errorf("expect 1,got %d", v)
+	}
+	checkList(t, l, []int{2})
+	// removing an element twice or from another list is a no-op
+	l.Remove(e1)
+	New[int]().Remove(l.Front())
+	checkList(t, l, []int{2})
+	l.Init()
+	checkList(t, l, []int{})
+}
+
+func TestList_PushBackElement(t *testing.T) {
+	l1 := New[int]()
+	l2 := New[int]()
+	e1 := l1.PushBack(1)
+	l1.PushBack(2)
+	l2.PushBack(3)
+	l2.PushBackElement(e1)
+	checkList(t, l1, []int{2})
+	checkList(t, l2, []int{3, 1})
+	l2.PushBackElement(l2.Front())
+	checkList(t, l2, []int{1, 3})
+	l1.Remove(l1.Front())
+	l2.PushBackElement(e1)
+	checkList(t, l2, []int{3, 1})
+}
+
+func TestList_PushFrontElement(t *testing.T) {
+	l1 := New[int]()
+	l2 := New[int]()
+	e1 := l1.PushBack(1)
+	l1.PushBack(2)
+	l2.PushBack(3)
+	l2.PushFrontElement(e1)
+	checkList(t, l1, []int{2})
+	checkList(t, l2, []int{1, 3})
+	l2.PushFrontElement(l2.Back())
+	checkList(t, l2, []int{3, 1})
+	l1.Remove(l1.Front())
+	l2.PushFrontElement(e1)
+	checkList(t, l2, []int{1, 3})
+}
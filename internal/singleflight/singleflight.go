@@ -0,0 +1,86 @@
+// Package singleflight make sure only one load runs for a key at a time, the callers asking for the same key
+// meanwhile wait for and share its result
+package singleflight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// call is a load in flight
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Group de-duplicate loads by key, the zero value is ready to use
+type Group[K comparable, V any] struct {
+	lock  sync.Mutex
+	calls map[K]*call[V]
+}
+
+// Do run fn for key unless there is a run in flight already, then wait for its result
+// fn runs with a ctx won't be cancelled with the caller's one, so a caller stops waiting when its ctx is done
+// without affecting the others, a panic in fn is returned as an error
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (V, error) {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		c = &call[V]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.run(context.WithoutCancel(ctx), key, c, fn)
+	}
+	g.lock.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var value V
+		return value, ctx.Err()
+	}
+}
+
+// Load return the value get finds for key, otherwise run loader for key as Do does and add its value by set,
+// peek is checked again in the run before loader, so a caller missing key just before a previous run added it
+// doesn't load it again, the loader run is recorded by stats and a failed load is not added
+func (g *Group[K, V]) Load(ctx context.Context, key K, get, peek func(K) (V, bool), set func(K, V) bool,
+	loader cache.Loader[K, V], stats *cache.Recorder) (V, error) {
+	if v, ok := get(key); ok {
+		return v, nil
+	}
+	return g.Do(ctx, key, func(ctx context.Context) (V, error) {
+		if v, ok := peek(key); ok {
+			return v, nil
+		}
+		start := time.Now()
+		value, err := loader(ctx, key)
+		stats.Load(time.Since(start), err)
+		if err == nil {
+			set(key, value)
+		}
+		return value, err
+	})
+}
+
+// run fn then publish its result to the waiters
+func (g *Group[K, V]) run(ctx context.Context, key K, c *call[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("singleflight: load of %v panicked: %v", key, r)
+		}
+		g.lock.Lock()
+		delete(g.calls, key)
+		g.lock.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn(ctx)
+}
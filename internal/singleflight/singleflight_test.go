@@ -0,0 +1,149 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var (
+		g     Group[string, int]
+		calls int32
+		ready sync.WaitGroup
+		wg    sync.WaitGroup
+	)
+	ready.Add(10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+				atomic.AddInt32(&calls, 1)
+				// hold the load until every caller has asked for it
+				ready.Wait()
+				return 42, nil
+			})
+			if v != 42 || err != nil {
+				t.Errorf("expect 42 with nil,got %v with %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expect 1,got %d", calls)
+	}
+}
+
+func TestGroup_DoCancel(t *testing.T) {
+	var (
+		g       Group[string, int]
+		release = make(chan struct{})
+		started = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		v, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 42, nil
+		})
+		if v != 42 || err != nil {
+			t.Errorf("expect 42 with nil,got %v with %v", v, err)
+		}
+	}()
+	<-started
+	// a waiter gives up without affecting the load
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Do(ctx, "key", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v,got %v", context.Canceled, err)
+	}
+	close(release)
+	<-done
+}
+
+func TestGroup_DoCancelStarter(t *testing.T) {
+	var (
+		g       Group[string, int]
+		release = make(chan struct{})
+		started = make(chan struct{})
+		loaded  = make(chan error, 1)
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		loaded <- ctx.Err()
+		return 42, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v,got %v", context.Canceled, err)
+	}
+	// the caller started the load gave up, but the load itself goes on
+	close(release)
+	if err := <-loaded; err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+}
+
+func TestGroup_DoPanic(t *testing.T) {
+	var g Group[string, int]
+	_, err := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Errorf("expect error,got nil")
+	}
+}
+
+func TestGroup_Load(t *testing.T) {
+	var (
+		g     Group[string, int]
+		stats cache.Recorder
+		items = make(map[string]int)
+		loads int
+	)
+	peek := func(key string) (int, bool) {
+		v, ok := items[key]
+		return v, ok
+	}
+	set := func(key string, value int) bool {
+		items[key] = value
+		return false
+	}
+	loader := func(ctx context.Context, key string) (int, error) {
+		loads++
+		return 42, nil
+	}
+	if v, err := g.Load(context.Background(), "key", peek, peek, set, loader, &stats); v != 42 || err != nil {
+		t.Errorf("expect 42 with nil,got %v with %v", v, err)
+	}
+	// a caller which missed the key just before the load above added it finds it in its own run
+	missed := func(key string) (int, bool) {
+		return 0, false
+	}
+	if v, err := g.Load(context.Background(), "key", missed, peek, set, loader, &stats); v != 42 || err != nil || loads != 1 {
+		t.Errorf("expect 42 with nil loaded once,got %v with %v loaded %d times", v, err, loads)
+	}
+	errLoad := errors.New("load failed")
+	_, err := g.Load(context.Background(), "other", peek, peek, set, func(ctx context.Context, key string) (int, error) {
+		return 0, errLoad
+	}, &stats)
+	if _, ok := items["other"]; !errors.Is(err, errLoad) || ok {
+		t.Errorf("expect %v not added,got %v added %v", errLoad, err, ok)
+	}
+	if s := stats.Stats(); s.LoadSuccesses != 1 || s.LoadFailures != 1 {
+		t.Errorf("expect 1 load success and 1 load failure,got %+v", s)
+	}
+}
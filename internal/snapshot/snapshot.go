@@ -0,0 +1,160 @@
+// Package snapshot implement the binary format caches are saved in, a header of magic, version and policy
+// followed by the sections each policy writes with varints and length prefixed blobs
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+const (
+	magic = "GCSN"
+	// Version is the version of format written
+	Version = 1
+	// the max length of a blob, so corrupted data can't make us allocate too much
+	maxBlob = 1 << 30
+)
+
+// Writer write a snapshot, the first error is kept and returned by Flush
+type Writer struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+// NewWriter return a Writer after writing the header of policy
+func NewWriter(w io.Writer, policy string) *Writer {
+	sw := &Writer{w: bufio.NewWriter(w)}
+	sw.write([]byte(magic))
+	sw.Uvarint(Version)
+	sw.Blob([]byte(policy))
+	return sw
+}
+
+// Uvarint write an unsigned integer
+func (w *Writer) Uvarint(v uint64) {
+	w.write(w.buf[:binary.PutUvarint(w.buf[:], v)])
+}
+
+// Varint write a signed integer
+func (w *Writer) Varint(v int64) {
+	w.write(w.buf[:binary.PutVarint(w.buf[:], v)])
+}
+
+// Blob write data prefixed by its length
+func (w *Writer) Blob(data []byte) {
+	w.Uvarint(uint64(len(data)))
+	w.write(data)
+}
+
+// Encode write v encoded by codec as a blob
+func Encode[T any](w *Writer, codec cache.Codec[T], v T) {
+	if w.err != nil {
+		return
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.Blob(data)
+}
+
+// Flush write the buffered data and return the first error happened
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) write(data []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(data)
+	}
+}
+
+// Reader read a snapshot, the first error is kept and returned by Err, the values read after it are zero
+type Reader struct {
+	r   *bufio.Reader
+	err error
+}
+
+// NewReader return a Reader after checking the header is of a supported version and policy
+func NewReader(r io.Reader, policy string) (*Reader, error) {
+	sr := &Reader{r: bufio.NewReader(r)}
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(sr.r, head); err != nil || string(head) != magic {
+		return nil, fmt.Errorf("%w: bad magic", cache.ErrInvalidSnapshot)
+	}
+	if v := sr.Uvarint(); v != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", cache.ErrInvalidSnapshot, v)
+	}
+	if p := string(sr.Blob()); p != policy {
+		return nil, fmt.Errorf("%w: snapshot of %q can't be restored into %q", cache.ErrInvalidSnapshot, p, policy)
+	}
+	return sr, sr.Err()
+}
+
+// Uvarint read an unsigned integer
+func (r *Reader) Uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.fail(err)
+	return v
+}
+
+// Varint read a signed integer
+func (r *Reader) Varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.fail(err)
+	return v
+}
+
+// Blob read data prefixed by its length
+func (r *Reader) Blob() []byte {
+	n := r.Uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if n > maxBlob {
+		r.fail(fmt.Errorf("blob of %d bytes", n))
+		return nil
+	}
+	data := make([]byte, n)
+	_, err := io.ReadFull(r.r, data)
+	r.fail(err)
+	return data
+}
+
+// Decode read a blob and decode it by codec
+func Decode[T any](r *Reader, codec cache.Codec[T]) (v T) {
+	data := r.Blob()
+	if r.err != nil {
+		return v
+	}
+	v, err := codec.Unmarshal(data)
+	r.fail(err)
+	return v
+}
+
+// Err return the first error happened
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// keep the first error, a truncated or undecodable snapshot is reported as invalid
+func (r *Reader) fail(err error) {
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%w: %v", cache.ErrInvalidSnapshot, err)
+	}
+}
@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+func TestWriter_Reader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "test")
+	w.Uvarint(42)
+	w.Varint(-42)
+	Encode[string](w, cache.GobCodec[string]{}, "gob")
+	Encode[string](w, cache.JSONCodec[string]{}, "json")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), "test")
+	if err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if v := r.Uvarint(); v != 42 {
+		t.Errorf("expect 42,got %d", v)
+	}
+	if v := r.Varint(); v != -42 {
+		t.Errorf("expect -42,got %d", v)
+	}
+	if v := Decode[string](r, cache.GobCodec[string]{}); v != "gob" {
+		t.Errorf("expect gob,got %q", v)
+	}
+	if v := Decode[string](r, cache.JSONCodec[string]{}); v != "json" {
+		t.Errorf("expect json,got %q", v)
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	// read past the end
+	if v := r.Uvarint(); v != 0 || !errors.Is(r.Err(), cache.ErrInvalidSnapshot) {
+		t.Errorf("expect 0 with ErrInvalidSnapshot,got %d with %v", v, r.Err())
+	}
+
+	if _, err := NewReader(bytes.NewReader(buf.Bytes()), "other"); !errors.Is(err, cache.ErrInvalidSnapshot) {
+		t.Errorf("expect ErrInvalidSnapshot,got %v", err)
+	}
+}
+
+func TestReader_Blob(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "test")
+	w.Uvarint(maxBlob + 1)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	r, err := NewReader(&buf, "test")
+	if err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if v := r.Blob(); v != nil || !errors.Is(r.Err(), cache.ErrInvalidSnapshot) {
+		t.Errorf("expect nil with ErrInvalidSnapshot,got %v with %v", v, r.Err())
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "test")
+	w.Blob([]byte("not json"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	r, err := NewReader(&buf, "test")
+	if err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if Decode[int](r, cache.JSONCodec[int]{}); !errors.Is(r.Err(), cache.ErrInvalidSnapshot) {
+		t.Errorf("expect ErrInvalidSnapshot,got %v", r.Err())
+	}
+}
@@ -1,9 +1,16 @@
-// Package lru implement a thread safe lfu cache
+// Package lfu implement a thread safe lfu cache
+// every operation takes constant time by the frequency list described in http://dhruvbird.com/lfu.pdf
 package lfu
 
 import (
-	"container/list"
+	"context"
+	"iter"
 	"sync"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
 )
 
 const (
@@ -11,179 +18,314 @@ const (
 	Default_LFU_Size = 1024
 )
 
-// LFU implements a thread safe fixed size LFU cache
-type LFU struct {
-	lock      sync.RWMutex
-	capacity  int
-	evictList *list.List
-	items     map[interface{}]*list.Element
-	misses    int
-	hits      int
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// LFU is a Cache holds arbitrary keys and values, kept for the callers written before Cache was type parameterized
+type LFU = Cache[interface{}, interface{}]
+
+// Cache implements a thread safe fixed size LFU cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	// frequencies hold a bucket for every frequency in use from the lowest to the highest
+	frequencies *list.List[*bucket[K, V]]
+	items       map[K]*list.Element[payload[K, V]]
+	stats       cache.Recorder
+	evicted     evict.Notifier[K, V]
+	loads       singleflight.Group[K, V]
+	keys        cache.Codec[K]
+	values      cache.Codec[V]
 }
 
-// payload contains the value evictList hold
-type payload struct {
-	key       interface{}
-	value     interface{}
+// bucket holds the items share the same frequency from the least recently to the most recently touched
+type bucket[K comparable, V any] struct {
 	frequency uint
+	items     list.List[payload[K, V]]
+}
+
+// payload contains the value bucket hold
+type payload[K comparable, V any] struct {
+	key    K
+	value  V
+	bucket *list.Element[*bucket[K, V]]
 }
 
 // NewLFUCache return a given size LFU
 func NewLFUCache(size int) *LFU {
+	return New[interface{}, interface{}](size)
+}
+
+// New return a given size LFU holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
 	if size <= 0 {
 		size = Default_LFU_Size
 	}
-	return &LFU{
-		capacity:  size,
-		evictList: list.New(),
-		items:     make(map[interface{}]*list.Element),
+	l := &Cache[K, V]{
+		capacity:    size,
+		frequencies: list.New[*bucket[K, V]](),
+		items:       make(map[K]*list.Element[payload[K, V]]),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.keys == nil {
+		l.keys = cache.GobCodec[K]{}
+	}
+	if l.values == nil {
+		l.values = cache.GobCodec[V]{}
 	}
+	return l
 }
 
 // return the LFU running information
-func (l *LFU) Info() (hits int, misses int, maxSize int, currentSize int) {
-	l.lock.RLock()
-	defer l.lock.RUnlock()
-	return l.hits, l.misses, l.capacity, l.Len()
+func (l *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := l.stats.Stats()
+	return int(s.Hits), int(s.Misses), l.capacity, l.Len()
+}
+
+// return a snapshot of the LFU statistics
+func (l *Cache[K, V]) Stats() cache.Stats {
+	return l.stats.Stats()
+}
+
+// ResetStats set all the LFU statistics to zero
+func (l *Cache[K, V]) ResetStats() {
+	l.stats.Reset()
 }
 
 // return the LFU max capacity
-func (l *LFU) Cap() int {
+func (l *Cache[K, V]) Cap() int {
 	return l.capacity
 }
 
-// Add a new item into LFU
-func (l *LFU) Set(key, value interface{}) (evicted bool) {
+// Add a new item into LFU, the least frequently used item is evicted if LFU is full
+func (l *Cache[K, V]) Set(key K, value V) (evicted bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	return l.set(key, value)
+}
+
+func (l *Cache[K, V]) set(key K, value V) (evicted bool) {
+	l.stats.Set()
 	// key has exists, update it to new value
 	if v, ok := l.items[key]; ok {
-		v.Value.(*payload).frequency += 1
-		v.Value.(*payload).value = value
-		l.adjust(v)
+		l.stats.Evict(cache.EvictReplaced)
+		l.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		l.increment(v)
 		return false
 	}
-	v := &payload{
-		key:   key,
-		value: value,
+	if len(l.items) >= l.capacity {
+		l.evict(l.oldest(), cache.EvictCapacity)
+		evicted = true
 	}
-	item := l.evictList.PushBack(v)
-	i := l.adjust(item)
-	l.items[key] = i
-	if l.evictList.Len() > l.capacity {
-		l.removeItem(l.evictList.Back())
-		return true
+
+	front := l.frequencies.Front()
+	if front == nil || front.Value.frequency != 0 {
+		front = l.frequencies.PushFront(&bucket[K, V]{})
 	}
-	return false
+	l.items[key] = front.Value.items.PushBack(payload[K, V]{
+		key:    key,
+		value:  value,
+		bucket: front,
+	})
+	return evicted
 }
 
 // Get value from LFU by key
-func (l *LFU) Get(key interface{}) (value interface{}, ok bool) {
+func (l *Cache[K, V]) Get(key K) (value V, ok bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	return l.get(key)
+}
+
+func (l *Cache[K, V]) get(key K) (value V, ok bool) {
 	v, ok := l.items[key]
-	if ok {
-		v.Value.(*payload).frequency += 1
-		l.adjust(v)
-		l.hits += 1
-	} else {
-		l.misses += 1
-		return nil, ok
+	if !ok {
+		l.stats.Miss()
+		return value, ok
 	}
-	return v.Value.(*payload).value, ok
+	l.increment(v)
+	l.stats.Hit()
+	return v.Value.value, ok
 }
 
 // Cotains check if the LRU contains the given key
-func (l *LFU) Contains(key interface{}) bool {
+func (l *Cache[K, V]) Contains(key K) bool {
+	_, ok := l.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (l *Cache[K, V]) peek(key K) (value V, ok bool) {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
-	_, ok := l.items[key]
-	return ok
+	v, ok := l.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
 }
 
 // Remove the given key item return if the key has existed before
-func (l *LFU) Remove(key interface{}) bool {
+func (l *Cache[K, V]) Remove(key K) bool {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
 	v, ok := l.items[key]
 	if ok {
-		l.removeItem(v)
+		l.evict(v, cache.EvictRemoved)
 	}
 	return ok
 }
 
-// Remove and return the oldest item from LFU
-func (l *LFU) PopOldest() (key, value interface{}) {
+// Remove and return the least frequently used item from LFU, the least recently used one among the same frequency
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (l *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = l.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the LFU is empty
+func (l *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
-	if l.evictList.Len() == 0 {
-		return nil, nil
+	defer l.evicted.Unlock(&l.lock)
+	v := l.oldest()
+	if v == nil {
+		return key, value, false
 	}
-	v := l.evictList.Back()
-	delete(l.items, v.Value.(*payload).key)
 	l.removeItem(v)
-	return v.Value.(*payload).key, v.Value.(*payload).value
+	return v.Value.key, v.Value.value, true
 }
 
 // return the value if the key exist, otherwise update the key by given value similar with redis SETNX
-func (l *LFU) GetOrSet(key, value interface{}) (newValue interface{}, isGet bool) {
-	if v, ok := l.Get(key); ok {
+func (l *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	l.lock.Lock()
+	defer l.evicted.Unlock(&l.lock)
+	if v, ok := l.get(key); ok {
 		return v, ok
 	}
-	l.Set(key, value)
-	l.misses += 1
+	l.set(key, value)
 	return value, false
 }
 
-// return all keys the LRU hold from oldest to newest
-func (l *LFU) Keys() []interface{} {
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into LFU
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (l *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return l.loads.Load(ctx, key, l.Get, l.peek, l.Set, loader, &l.stats)
+}
+
+// return all keys the LFU hold from the least frequently used to the most, the least recently used first among
+// the same frequency
+func (l *Cache[K, V]) Keys() []K {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
-	keys := make([]interface{}, 0)
-	for v := l.evictList.Back(); v != nil; v = v.Prev() {
-		keys = append(keys, v.Value.(*payload).key)
+	keys := make([]K, 0, len(l.items))
+	for b := l.frequencies.Front(); b != nil; b = b.Next() {
+		for v := b.Value.items.Front(); v != nil; v = v.Next() {
+			keys = append(keys, v.Value.key)
+		}
 	}
 	return keys
 }
 
+// All iterate over the items in the order of Keys without updating their recency or frequency, the items are
+// taken when the iteration starts so it's safe to modify the LFU in the loop
+func (l *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range l.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (l *Cache[K, V]) snapshot() []payload[K, V] {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(l.items))
+	for b := l.frequencies.Front(); b != nil; b = b.Next() {
+		for v := b.Value.items.Front(); v != nil; v = v.Next() {
+			items = append(items, v.Value)
+		}
+	}
+	return items
+}
+
 // return the LFU length
-func (l *LFU) Len() int {
+func (l *Cache[K, V]) Len() int {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
-	return l.evictList.Len()
+	return len(l.items)
 }
 
-// Purge use to clear all items in LFU
-func (l *LFU) Purge() {
+// Purge use to clear all items in LFU, the statistics are kept, call ResetStats as well to clear them
+func (l *Cache[K, V]) Purge() {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	l.purge()
+}
+
+// clear all items and report them as purged, the lock must be held
+func (l *Cache[K, V]) purge() {
+	for b := l.frequencies.Front(); b != nil; b = b.Next() {
+		for v := b.Value.items.Front(); v != nil; v = v.Next() {
+			l.stats.Evict(cache.EvictPurged)
+			l.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+		}
+	}
 	for k := range l.items {
 		delete(l.items, k)
 	}
-	l.evictList.Init()
+	l.frequencies.Init()
 }
 
-// adjust the list element to correct location
-func (l *LFU) adjust(i *list.Element) *list.Element {
-	if i.Prev() == nil {
-		return i
+// increment the frequency of item by moving it to the bucket of next frequency
+func (l *Cache[K, V]) increment(e *list.Element[payload[K, V]]) {
+	current := e.Value.bucket
+	frequency := current.Value.frequency + 1
+	next := current.Next()
+	if next == nil || next.Value.frequency != frequency {
+		// the item is the only one in its bucket, reuse the bucket for the next frequency
+		if current.Value.items.Len() == 1 {
+			current.Value.frequency = frequency
+			return
+		}
+		next = l.frequencies.InsertAfter(&bucket[K, V]{frequency: frequency}, current)
 	}
-	if i.Value.(*payload).frequency >= l.evictList.Front().Value.(*payload).frequency {
-		l.evictList.MoveBefore(i, l.evictList.Front())
-		return i
+	next.Value.items.PushBackElement(e)
+	e.Value.bucket = next
+	if current.Value.items.Len() == 0 {
+		l.frequencies.Remove(current)
 	}
-	for n := i; n != nil; n = n.Prev() {
-		if i.Value.(*payload).frequency < n.Value.(*payload).frequency {
-			l.evictList.MoveAfter(i, n)
-			return i
-		}
+}
+
+// return the item would be evicted next, nil if LFU is empty
+func (l *Cache[K, V]) oldest() *list.Element[payload[K, V]] {
+	if b := l.frequencies.Front(); b != nil {
+		return b.Value.items.Front()
 	}
-	l.evictList.MoveBefore(i, l.evictList.Front())
 	return nil
 }
 
-// remove item from lru
-func (l *LFU) removeItem(e *list.Element) {
-	l.evictList.Remove(e)
-	delete(l.items, e.Value.(*payload).key)
+// remove item from lfu and notify the eviction callback
+func (l *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	l.removeItem(e)
+	l.stats.Evict(reason)
+	l.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from lfu, drop its bucket if it becomes empty
+func (l *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	b := e.Value.bucket
+	b.Value.items.Remove(e)
+	if b.Value.items.Len() == 0 {
+		l.frequencies.Remove(b)
+	}
+	delete(l.items, e.Value.key)
 }
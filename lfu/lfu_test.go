@@ -1,6 +1,12 @@
 package lfu
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+)
 
 func TestLFU_Set(t *testing.T) {
 	s := NewLFUCache(32)
@@ -118,6 +124,49 @@ func TestLFU_Remove(t *testing.T) {
 	}
 }
 
+func TestLFU_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestLFU_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestLFU_Frequency(t *testing.T) {
+	s := New[string, int](3)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.Set("c", 3)
+	s.Get("a")
+	s.Get("a")
+	s.Get("c")
+	if keys := s.Keys(); fmt.Sprint(keys) != "[b c a]" {
+		t.Errorf("expect [b c a],got %v", keys)
+	}
+	// b has the lowest frequency
+	s.Set("d", 4)
+	if ok := s.Contains("b"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	// d is the newest one with the lowest frequency, but it's still evicted before c
+	s.Get("d")
+	if keys := s.Keys(); fmt.Sprint(keys) != "[c d a]" {
+		t.Errorf("expect [c d a],got %v", keys)
+	}
+	if k, v := s.PopOldest(); k != "c" || v != 3 {
+		t.Errorf("expect c,3;got %v,%v", k, v)
+	}
+	s.Remove("d")
+	s.Set("e", 5)
+	if keys := s.Keys(); fmt.Sprint(keys) != "[e a]" {
+		t.Errorf("expect [e a],got %v", keys)
+	}
+}
+
 func BenchmarkLFU_Set(b *testing.B) {
 	b.StopTimer()
 	s := NewLFUCache(8096)
@@ -145,3 +194,52 @@ func BenchmarkLFU_GetNotExist(b *testing.B) {
 		s.Get("key")
 	}
 }
+
+func TestLFU_Typed(t *testing.T) {
+	s := New[string, int](2)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	if v, ok := s.Get("a"); !ok || v != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", v, ok)
+	}
+	if v, ok := s.Get("c"); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if k, v := s.PopOldest(); k != "b" || v != 2 {
+		t.Errorf("expect b,2;got %v,%v", k, v)
+	}
+}
+
+// the cost per operation shall stay flat as the capacity grows
+var benchmarkSizes = []int{1 << 10, 1 << 14, 1 << 17, 1 << 20}
+
+func BenchmarkLFU_GetBySize(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			s := New[int, int](size)
+			for i := 0; i < size; i++ {
+				s.Set(i, i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Get(i % size)
+			}
+		})
+	}
+}
+
+func BenchmarkLFU_SetBySize(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			s := New[int, int](size)
+			for i := 0; i < size; i++ {
+				s.Set(i, i)
+				s.Get(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Set(size+i, i)
+			}
+		})
+	}
+}
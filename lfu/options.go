@@ -0,0 +1,21 @@
+package lfu
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the LFU created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the LFU except the ones returned by PopOldest
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.evicted.SetCallback(fn)
+	}
+}
+
+// WithCodec set the codecs the keys and values are encoded by in Snapshot and Restore, cache.GobCodec by default
+func WithCodec[K comparable, V any](keys cache.Codec[K], values cache.Codec[V]) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.keys = keys
+		l.values = values
+	}
+}
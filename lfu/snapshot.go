@@ -0,0 +1,96 @@
+package lfu
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/snapshot"
+)
+
+// the policy name written in the snapshot header
+const policy = "lfu"
+
+// entry is an item written in the snapshot along with its frequency
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	frequency uint
+}
+
+// Snapshot write the items LFU hold in the order of Keys along with their frequencies into w, the keys and values
+// are encoded by the codecs set by WithCodec
+func (l *Cache[K, V]) Snapshot(w io.Writer) error {
+	entries := l.entries()
+	sw := snapshot.NewWriter(w, policy)
+	sw.Uvarint(uint64(len(entries)))
+	for _, v := range entries {
+		snapshot.Encode(sw, l.keys, v.key)
+		snapshot.Encode(sw, l.values, v.value)
+		sw.Uvarint(uint64(v.frequency))
+	}
+	return sw.Flush()
+}
+
+// Restore replace the items of LFU by the ones written by Snapshot, the current items are reported as purged,
+// only the most frequently used ones are kept if there are more than the capacity, the others are evicted by
+// capacity as Set does
+// LFU is left unchanged if r doesn't hold a valid snapshot
+func (l *Cache[K, V]) Restore(r io.Reader) error {
+	sr, err := snapshot.NewReader(r, policy)
+	if err != nil {
+		return err
+	}
+	n := sr.Uvarint()
+	entries := make([]entry[K, V], 0, min(n, uint64(l.capacity)))
+	for i := uint64(0); i < n && sr.Err() == nil; i++ {
+		v := entry[K, V]{
+			key:       snapshot.Decode(sr, l.keys),
+			value:     snapshot.Decode(sr, l.values),
+			frequency: uint(sr.Uvarint()),
+		}
+		if len(entries) > 0 && v.frequency < entries[len(entries)-1].frequency {
+			return fmt.Errorf("%w: frequencies out of order", cache.ErrInvalidSnapshot)
+		}
+		entries = append(entries, v)
+	}
+	if err := sr.Err(); err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.evicted.Unlock(&l.lock)
+	l.purge()
+	for _, v := range entries {
+		// a key written twice keeps the later one
+		if e, ok := l.items[v.key]; ok {
+			l.removeItem(e)
+		}
+		back := l.frequencies.Back()
+		if back == nil || back.Value.frequency != v.frequency {
+			back = l.frequencies.PushBack(&bucket[K, V]{frequency: v.frequency})
+		}
+		l.items[v.key] = back.Value.items.PushBack(payload[K, V]{
+			key:    v.key,
+			value:  v.value,
+			bucket: back,
+		})
+	}
+	for len(l.items) > l.capacity {
+		l.evict(l.oldest(), cache.EvictCapacity)
+	}
+	return nil
+}
+
+// return the items in the order of Keys along with their frequencies
+func (l *Cache[K, V]) entries() []entry[K, V] {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	entries := make([]entry[K, V], 0, len(l.items))
+	for b := l.frequencies.Front(); b != nil; b = b.Next() {
+		for v := b.Value.items.Front(); v != nil; v = v.Next() {
+			entries = append(entries, entry[K, V]{key: v.Value.key, value: v.Value.value, frequency: b.Value.frequency})
+		}
+	}
+	return entries
+}
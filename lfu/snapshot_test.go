@@ -0,0 +1,97 @@
+package lfu
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/snapshot"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLFU_Snapshot(t *testing.T) {
+	s := New[string, int](32)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.Set("c", 3)
+	s.Get("a")
+	s.Get("a")
+	s.Get("c")
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	r := New[string, int](32)
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if diff := cmp.Diff([]string{"b", "c", "a"}, r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the frequencies are restored, b is touched once to catch up with c
+	r.Get("b")
+	r.Set("d", 4)
+	if diff := cmp.Diff([]string{"d", "c", "b", "a"}, r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLFU_RestoreSmaller(t *testing.T) {
+	s := New[int, int](8)
+	for i := range 8 {
+		s.Set(i, i)
+		for range i {
+			s.Get(i)
+		}
+	}
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	var evicted []int
+	r := New[int, int](3, WithOnEvict(func(key, value int, reason cache.EvictReason) {
+		if reason == cache.EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	}))
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if diff := cmp.Diff([]int{5, 6, 7}, r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the less frequently used items beyond the capacity are evicted as Set does
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 4}, evicted); diff != "" {
+		t.Errorf("evicted mismatch (-want +got):\n%s", diff)
+	}
+	if s := r.Stats(); s.Evictions[cache.EvictCapacity] != 5 {
+		t.Errorf("expect 5 capacity evictions,got %v", s.Evictions)
+	}
+}
+
+func TestLFU_RestoreInvalid(t *testing.T) {
+	// frequencies must be in ascending order
+	var buf bytes.Buffer
+	w := snapshot.NewWriter(&buf, policy)
+	w.Uvarint(2)
+	for _, f := range []uint64{2, 1} {
+		snapshot.Encode[int](w, cache.GobCodec[int]{}, int(f))
+		snapshot.Encode[int](w, cache.GobCodec[int]{}, int(f))
+		w.Uvarint(f)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	s := New[int, int](32)
+	if err := s.Restore(&buf); !errors.Is(err, cache.ErrInvalidSnapshot) {
+		t.Errorf("expect ErrInvalidSnapshot,got %v", err)
+	}
+
+	// a snapshot of another policy
+	buf.Reset()
+	snapshot.NewWriter(&buf, "lru").Flush()
+	if err := s.Restore(&buf); !errors.Is(err, cache.ErrInvalidSnapshot) {
+		t.Errorf("expect ErrInvalidSnapshot,got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package lru
+
+import (
+	"time"
+	"weak"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/list"
+)
+
+// RemoveExpired remove all expired items from LRU, return how many were removed
+func (l *Cache[K, V]) RemoveExpired() int {
+	l.lock.Lock()
+	defer l.evicted.Unlock(&l.lock)
+	var removed int
+	for v := l.evictList.Back(); v != nil; {
+		prev := v.Prev()
+		if l.expired(v) {
+			l.evict(v, cache.EvictExpired)
+			removed++
+		}
+		v = prev
+	}
+	return removed
+}
+
+// Close stop the janitor started by WithJanitor at once, it's safe to call more than once, a LRU dropped without
+// Close is not pinned by its janitor, which stops at the first tick after the LRU is garbage collected
+func (l *Cache[K, V]) Close() {
+	l.closeOnce.Do(func() {
+		if l.stopJanitor != nil {
+			close(l.stopJanitor)
+		}
+	})
+}
+
+// runJanitor remove expired items every interval until stop is closed or the LRU is garbage collected,
+// the LRU is held by a weak pointer so the goroutine doesn't keep a dropped LRU alive
+func runJanitor[K comparable, V any](lru weak.Pointer[Cache[K, V]], interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l := lru.Value()
+			if l == nil {
+				return
+			}
+			l.RemoveExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// check if the item has expired
+func (l *Cache[K, V]) expired(e *list.Element[payload[K, V]]) bool {
+	return e.Value.expireAt != 0 && l.now().UnixNano() >= e.Value.expireAt
+}
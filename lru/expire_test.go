@@ -0,0 +1,161 @@
+package lru
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLRU_SetWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	s := New[string, int](32, WithClock[string, int](clock.Now))
+	s.SetWithTTL("short", 1, time.Second)
+	s.SetWithTTL("long", 2, time.Minute)
+	s.Set("forever", 3)
+	clock.Advance(time.Second)
+	if v, ok := s.Get("short"); ok {
+		t.Errorf("expect false,got %v with %v", v, ok)
+	}
+	if ok := s.Contains("short"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if v, ok := s.Get("long"); !ok || v != 2 {
+		t.Errorf("expect 2 with true,got %v with %v", v, ok)
+	}
+	clock.Advance(time.Hour)
+	if ok := s.Contains("long"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if keys := s.Keys(); len(keys) != 1 || keys[0] != "forever" {
+		t.Errorf("expect [forever],got %v", keys)
+	}
+	if v, ok := s.Get("forever"); !ok || v != 3 {
+		t.Errorf("expect 3 with true,got %v with %v", v, ok)
+	}
+}
+
+func TestLRU_WithDefaultTTL(t *testing.T) {
+	clock := newFakeClock()
+	s := New[string, int](32, WithClock[string, int](clock.Now), WithDefaultTTL[string, int](time.Second))
+	s.Set("key", 1)
+	s.SetWithTTL("other", 2, 0)
+	clock.Advance(time.Second / 2)
+	// updating an item restarts its TTL
+	s.Set("key", 3)
+	clock.Advance(time.Second / 2)
+	if v, ok := s.GetOrSet("key", 4); !ok || v != 3 {
+		t.Errorf("expect 3 with true,got %v with %v", v, ok)
+	}
+	clock.Advance(time.Second)
+	if v, ok := s.GetOrSet("key", 4); ok || v != 4 {
+		t.Errorf("expect 4 with false,got %v with %v", v, ok)
+	}
+	if ok := s.Contains("other"); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+}
+
+func TestLRU_RemoveExpired(t *testing.T) {
+	clock := newFakeClock()
+	s := New[int, int](32, WithClock[int, int](clock.Now))
+	for i := 0; i < 10; i++ {
+		s.SetWithTTL(i, i, time.Duration(i+1)*time.Second)
+	}
+	clock.Advance(5 * time.Second)
+	if n := s.RemoveExpired(); n != 5 {
+		t.Errorf("expect 5,got %d", n)
+	}
+	if l := s.Len(); l != 5 {
+		t.Errorf("expect 5,got %d", l)
+	}
+	if k, v := s.PopOldest(); k != 5 || v != 5 {
+		t.Errorf("expect 5,5;got %v,%v", k, v)
+	}
+	clock.Advance(time.Hour)
+	if k, v := s.PopOldest(); k != 0 || v != 0 {
+		t.Errorf("expect 0,0;got %v,%v", k, v)
+	}
+	if l := s.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestLRU_WithJanitor(t *testing.T) {
+	clock := newFakeClock()
+	s := New[int, int](32, WithClock[int, int](clock.Now), WithJanitor[int, int](time.Millisecond))
+	defer s.Close()
+	s.SetWithTTL(1, 1, time.Second)
+	clock.Advance(time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expect janitor to remove expired item,got %d items", s.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.Close()
+	s.Close()
+}
+
+func TestLRU_JanitorStop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	waitGoroutines := func(stop func()) {
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Fatalf("expect janitor to stop,got %d goroutines,%d before", runtime.NumGoroutine(), before)
+			}
+			stop()
+			time.Sleep(time.Millisecond)
+		}
+	}
+	s := New[int, int](32, WithJanitor[int, int](time.Millisecond))
+	waitGoroutines(s.Close)
+	// the janitor of a dropped LRU stops once the LRU is garbage collected
+	New[int, int](32, WithJanitor[int, int](time.Millisecond)).Set(1, 1)
+	waitGoroutines(runtime.GC)
+}
+
+func TestLRU_OnEvictExpired(t *testing.T) {
+	clock := newFakeClock()
+	var expired []int
+	s := New[int, int](32, WithClock[int, int](clock.Now), WithOnEvict(func(key, value int, reason cache.EvictReason) {
+		if reason == cache.EvictExpired {
+			expired = append(expired, key)
+		}
+	}))
+	s.SetWithTTL(1, 1, time.Second)
+	s.SetWithTTL(2, 2, time.Second)
+	s.SetWithTTL(3, 3, time.Minute)
+	clock.Advance(time.Second)
+	s.Get(1)
+	s.RemoveExpired()
+	if len(expired) != 2 || expired[0] != 1 || expired[1] != 2 {
+		t.Errorf("expect [1 2],got %v", expired)
+	}
+}
@@ -2,8 +2,16 @@
 package lru
 
 import (
-	"container/list"
+	"context"
+	"iter"
 	"sync"
+	"time"
+	"weak"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
 )
 
 const (
@@ -11,159 +19,300 @@ const (
 	Default_LRU_Size = 1024
 )
 
-// LRU implements a thread safe fixed size LRU cache
-type LRU struct {
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// LRU is a Cache holds arbitrary keys and values, kept for the callers written before Cache was type parameterized
+type LRU = Cache[interface{}, interface{}]
+
+// Cache implements a thread safe fixed size LRU cache
+type Cache[K comparable, V any] struct {
 	lock      sync.RWMutex
 	capacity  int
-	evictList *list.List
-	items     map[interface{}]*list.Element
-	misses    int
-	hits      int
+	evictList *list.List[payload[K, V]]
+	items     map[K]*list.Element[payload[K, V]]
+	stats     cache.Recorder
+	evicted   evict.Notifier[K, V]
+	loads     singleflight.Group[K, V]
+	keys      cache.Codec[K]
+	values    cache.Codec[V]
+
+	ttl         time.Duration
+	now         func() time.Time
+	janitor     time.Duration
+	stopJanitor chan struct{}
+	closeOnce   sync.Once
 }
 
 // payload contains the value evictList hold
-type payload struct {
-	key   interface{}
-	value interface{}
+type payload[K comparable, V any] struct {
+	key   K
+	value V
+	// expireAt is the unix nano the item expires at, zero means never
+	expireAt int64
 }
 
 // NewLRUCache return a given size LRU
 func NewLRUCache(size int) *LRU {
+	return New[interface{}, interface{}](size)
+}
+
+// New return a given size LRU holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
 	if size <= 0 {
 		size = Default_LRU_Size
 	}
-	return &LRU{
+	l := &Cache[K, V]{
 		capacity:  size,
-		evictList: list.New(),
-		items:     make(map[interface{}]*list.Element),
+		evictList: list.New[payload[K, V]](),
+		items:     make(map[K]*list.Element[payload[K, V]]),
+		now:       time.Now,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.keys == nil {
+		l.keys = cache.GobCodec[K]{}
+	}
+	if l.values == nil {
+		l.values = cache.GobCodec[V]{}
+	}
+	if l.janitor > 0 {
+		l.stopJanitor = make(chan struct{})
+		go runJanitor(weak.Make(l), l.janitor, l.stopJanitor)
+	}
+	return l
 }
 
 // return the LRU running information
-func (l *LRU) Info() (hits int, misses int, maxSize int, currentSize int) {
-	l.lock.RLock()
-	defer l.lock.RUnlock()
-	return l.hits, l.misses, l.capacity, l.Len()
+func (l *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := l.stats.Stats()
+	return int(s.Hits), int(s.Misses), l.capacity, l.Len()
+}
+
+// return a snapshot of the LRU statistics
+func (l *Cache[K, V]) Stats() cache.Stats {
+	return l.stats.Stats()
+}
+
+// ResetStats set all the LRU statistics to zero
+func (l *Cache[K, V]) ResetStats() {
+	l.stats.Reset()
 }
 
 // return the LRU max capacity
-func (l *LRU) Cap() int {
+func (l *Cache[K, V]) Cap() int {
 	return l.capacity
 }
 
-// Add a new item into LRU
-func (l *LRU) Set(key, value interface{}) (evicted bool) {
+// Add a new item into LRU, it expires after the default TTL if there is one
+func (l *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	return l.SetWithTTL(key, value, l.ttl)
+}
+
+// SetWithTTL add a new item into LRU which expires after ttl, a non-positive ttl means never
+func (l *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) (evicted bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	return l.set(key, value, ttl)
+}
+
+func (l *Cache[K, V]) set(key K, value V, ttl time.Duration) (evicted bool) {
+	l.stats.Set()
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = l.now().Add(ttl).UnixNano()
+	}
 	// key has exists, update it to new value
 	if v, ok := l.items[key]; ok {
 		l.evictList.MoveToFront(v)
-		v.Value.(*payload).value = value
+		l.stats.Evict(cache.EvictReplaced)
+		l.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		v.Value.expireAt = expireAt
 		return false
 	}
 
-	v := &payload{
-		key:   key,
-		value: value,
+	v := payload[K, V]{
+		key:      key,
+		value:    value,
+		expireAt: expireAt,
 	}
 	item := l.evictList.PushFront(v)
 	l.items[key] = item
 	if l.evictList.Len() > l.capacity {
-		l.removeItem(l.evictList.Back())
+		l.evict(l.evictList.Back(), cache.EvictCapacity)
 		return true
 	}
 	return false
 }
 
 // Get value from LRU by key
-func (l *LRU) Get(key interface{}) (value interface{}, ok bool) {
+func (l *Cache[K, V]) Get(key K) (value V, ok bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	return l.get(key)
+}
+
+func (l *Cache[K, V]) get(key K) (value V, ok bool) {
 	v, ok := l.items[key]
-	if ok {
-		l.evictList.MoveToFront(v)
-		l.hits += 1
-	} else {
-		l.misses += 1
-		return nil, ok
+	if ok && l.expired(v) {
+		l.evict(v, cache.EvictExpired)
+		ok = false
+	}
+	if !ok {
+		l.stats.Miss()
+		return value, ok
 	}
-	return v.Value.(*payload).value, ok
+	l.evictList.MoveToFront(v)
+	l.stats.Hit()
+	return v.Value.value, ok
 }
 
 // Cotains check if the LRU contains the given key
-func (l *LRU) Contains(key interface{}) bool {
+func (l *Cache[K, V]) Contains(key K) bool {
+	_, ok := l.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (l *Cache[K, V]) peek(key K) (value V, ok bool) {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
-	_, ok := l.items[key]
-	return ok
+	v, ok := l.items[key]
+	if !ok || l.expired(v) {
+		return value, false
+	}
+	return v.Value.value, true
 }
 
 // Remove the given key item return if the key has existed before
-func (l *LRU) Remove(key interface{}) bool {
+func (l *Cache[K, V]) Remove(key K) bool {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
 	v, ok := l.items[key]
 	if ok {
-		l.removeItem(v)
+		l.evict(v, cache.EvictRemoved)
 	}
 	return ok
 }
 
-// Remove and return the oldest item from LRU
-func (l *LRU) PopOldest() (key, value interface{}) {
+// Remove and return the oldest item from LRU, expired items are dropped on the way
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (l *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = l.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the LRU is empty
+func (l *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
 	l.lock.Lock()
-	defer l.lock.Unlock()
-	if l.evictList.Len() == 0 {
-		return nil, nil
+	defer l.evicted.Unlock(&l.lock)
+	for v := l.evictList.Back(); v != nil; v = l.evictList.Back() {
+		if l.expired(v) {
+			l.evict(v, cache.EvictExpired)
+			continue
+		}
+		l.removeItem(v)
+		return v.Value.key, v.Value.value, true
 	}
-	v := l.evictList.Back()
-	delete(l.items, v.Value.(*payload).key)
-	l.removeItem(l.evictList.Back())
-	return v.Value.(*payload).key, v.Value.(*payload).value
+	return key, value, false
 }
 
 // return the value if the key exist, otherwise update the key by given value similar with redis SETNX
-func (l *LRU) GetOrSet(key, value interface{}) (newValue interface{}, isGet bool) {
-	if v, ok := l.Get(key); ok {
+func (l *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	l.lock.Lock()
+	defer l.evicted.Unlock(&l.lock)
+	if v, ok := l.get(key); ok {
 		return v, ok
 	}
-	l.Set(key, value)
-	l.misses += 1
+	l.set(key, value, l.ttl)
 	return value, false
 }
 
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into LRU with the default TTL
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (l *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return l.loads.Load(ctx, key, l.Get, l.peek, l.Set, loader, &l.stats)
+}
+
 // return all keys the LRU hold from oldest to newest
-func (l *LRU) Keys() []interface{} {
+func (l *Cache[K, V]) Keys() []K {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
-	keys := make([]interface{}, 0)
+	keys := make([]K, 0, l.evictList.Len())
 	for v := l.evictList.Back(); v != nil; v = v.Prev() {
-		keys = append(keys, v.Value.(*payload).key)
+		if !l.expired(v) {
+			keys = append(keys, v.Value.key)
+		}
 	}
 	return keys
 }
 
-// return the LRU length
-func (l *LRU) Len() int {
+// All iterate over the items in the order of Keys without updating their recency or frequency, the items are
+// taken when the iteration starts so it's safe to modify the LRU in the loop
+func (l *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range l.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (l *Cache[K, V]) snapshot() []payload[K, V] {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(l.items))
+	for v := l.evictList.Back(); v != nil; v = v.Prev() {
+		if !l.expired(v) {
+			items = append(items, v.Value)
+		}
+	}
+	return items
+}
+
+// return the LRU length, expired items which haven't been removed yet are counted
+func (l *Cache[K, V]) Len() int {
 	l.lock.RLock()
 	defer l.lock.RUnlock()
 	return l.evictList.Len()
 }
 
-// Purge use to clear all items in LRU
-func (l *LRU) Purge() {
+// Purge use to clear all items in LRU, the statistics are kept, call ResetStats as well to clear
+// them as Purge did before the statistics were added
+func (l *Cache[K, V]) Purge() {
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	defer l.evicted.Unlock(&l.lock)
+	l.purge()
+}
+
+// clear all items and report them as purged, the lock must be held
+func (l *Cache[K, V]) purge() {
+	for v := l.evictList.Back(); v != nil; v = v.Prev() {
+		l.stats.Evict(cache.EvictPurged)
+		l.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+	}
 	for k := range l.items {
 		delete(l.items, k)
 	}
 	l.evictList.Init()
-	l.misses = 0
-	l.hits = 0
+}
+
+// remove item from lru and notify the eviction callback
+func (l *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	l.removeItem(e)
+	l.stats.Evict(reason)
+	l.evicted.Add(e.Value.key, e.Value.value, reason)
 }
 
 // remove item from lru
-func (l *LRU) removeItem(e *list.Element) {
+func (l *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
 	l.evictList.Remove(e)
-	delete(l.items, e.Value.(*payload).key)
+	delete(l.items, e.Value.key)
 }
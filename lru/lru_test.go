@@ -1,6 +1,11 @@
 package lru
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+)
 
 func TestLRU_Set(t *testing.T) {
 	s := NewLRUCache(32)
@@ -118,6 +123,18 @@ func TestLRU_Remove(t *testing.T) {
 	}
 }
 
+func TestLRU_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestLRU_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
 func BenchmarkLRU_Set(b *testing.B) {
 	b.StopTimer()
 	s := NewLRUCache(8096)
@@ -145,3 +162,29 @@ func BenchmarkLRU_GetNotExist(b *testing.B) {
 		s.Get("key")
 	}
 }
+
+// BenchmarkLRU_ParallelGet is the baseline of the policies whose hits take only the read lock, every LRU hit takes
+// the write lock to move the item
+func BenchmarkLRU_ParallelGet(b *testing.B) {
+	cachetest.ParallelGet(b, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestLRU_Typed(t *testing.T) {
+	s := New[string, int](2)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	if v, ok := s.Get("a"); !ok || v != 1 {
+		t.Errorf("expect 1 with true,got %v with %v", v, ok)
+	}
+	if evicted := s.Set("c", 3); !evicted {
+		t.Errorf("expect true,got %v", evicted)
+	}
+	if v, ok := s.Get("b"); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if k, v := s.PopOldest(); k != "a" || v != 1 {
+		t.Errorf("expect a,1;got %v,%v", k, v)
+	}
+}
@@ -0,0 +1,47 @@
+package lru
+
+import (
+	"time"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+// Option configure the LRU created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithDefaultTTL make items added by Set expire after ttl
+func WithDefaultTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.ttl = ttl
+	}
+}
+
+// WithClock replace time.Now as the source of the current time used by expiration
+func WithClock[K comparable, V any](now func() time.Time) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.now = now
+	}
+}
+
+// WithJanitor start a background goroutine removes expired items every interval, it runs until Close is called
+// or the LRU is garbage collected
+func WithJanitor[K comparable, V any](interval time.Duration) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.janitor = interval
+	}
+}
+
+// WithOnEvict register a callback invoked for every item leaving the LRU except the ones returned by PopOldest
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.evicted.SetCallback(fn)
+	}
+}
+
+// WithCodec set the codecs the keys and values are encoded by in Snapshot and Restore, cache.GobCodec by default
+func WithCodec[K comparable, V any](keys cache.Codec[K], values cache.Codec[V]) Option[K, V] {
+	return func(l *Cache[K, V]) {
+		l.keys = keys
+		l.values = values
+	}
+}
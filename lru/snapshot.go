@@ -0,0 +1,67 @@
+package lru
+
+import (
+	"io"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/snapshot"
+)
+
+// the policy name written in the snapshot header
+const policy = "lru"
+
+// Snapshot write the items LRU hold from oldest to newest along with their expiration into w, the keys and values
+// are encoded by the codecs set by WithCodec, expired items are skipped
+func (l *Cache[K, V]) Snapshot(w io.Writer) error {
+	items := l.snapshot()
+	sw := snapshot.NewWriter(w, policy)
+	sw.Uvarint(uint64(len(items)))
+	for _, v := range items {
+		snapshot.Encode(sw, l.keys, v.key)
+		snapshot.Encode(sw, l.values, v.value)
+		sw.Varint(v.expireAt)
+	}
+	return sw.Flush()
+}
+
+// Restore replace the items of LRU by the ones written by Snapshot, the current items are reported as purged,
+// the items have expired since are dropped and only the newest ones are kept if there are more than the capacity,
+// the older ones are evicted by capacity as Set does
+// LRU is left unchanged if r doesn't hold a valid snapshot
+func (l *Cache[K, V]) Restore(r io.Reader) error {
+	sr, err := snapshot.NewReader(r, policy)
+	if err != nil {
+		return err
+	}
+	n := sr.Uvarint()
+	items := make([]payload[K, V], 0, min(n, uint64(l.capacity)))
+	for i := uint64(0); i < n && sr.Err() == nil; i++ {
+		items = append(items, payload[K, V]{
+			key:      snapshot.Decode(sr, l.keys),
+			value:    snapshot.Decode(sr, l.values),
+			expireAt: sr.Varint(),
+		})
+	}
+	if err := sr.Err(); err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.evicted.Unlock(&l.lock)
+	l.purge()
+	now := l.now().UnixNano()
+	for _, v := range items {
+		if v.expireAt != 0 && now >= v.expireAt {
+			continue
+		}
+		// a key written twice keeps the newer one
+		if e, ok := l.items[v.key]; ok {
+			l.removeItem(e)
+		}
+		l.items[v.key] = l.evictList.PushFront(v)
+	}
+	for l.evictList.Len() > l.capacity {
+		l.evict(l.evictList.Back(), cache.EvictCapacity)
+	}
+	return nil
+}
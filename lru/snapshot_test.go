@@ -0,0 +1,115 @@
+package lru
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLRU_Snapshot(t *testing.T) {
+	clock := newFakeClock()
+	s := New[string, int](32, WithClock[string, int](clock.Now))
+	s.Set("a", 1)
+	s.SetWithTTL("b", 2, time.Second)
+	s.SetWithTTL("c", 3, time.Minute)
+	s.Set("d", 4)
+	s.Get("a")
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+
+	clock.Advance(time.Second)
+	r := New[string, int](32, WithClock[string, int](clock.Now))
+	r.Set("x", 0)
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if diff := cmp.Diff([]string{"c", "d", "a"}, r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	if v, ok := r.Get("c"); !ok || v != 3 {
+		t.Errorf("expect 3,true;got %v,%v", v, ok)
+	}
+	clock.Advance(time.Minute)
+	if r.Contains("c") {
+		t.Errorf("expect c to keep its expiration")
+	}
+}
+
+func TestLRU_RestoreSmaller(t *testing.T) {
+	s := New[int, int](8)
+	for i := range 8 {
+		s.Set(i, i)
+	}
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	evicted := make(map[cache.EvictReason][]int)
+	r := New[int, int](3, WithOnEvict(func(key, value int, reason cache.EvictReason) {
+		evicted[reason] = append(evicted[reason], key)
+	}))
+	r.Set(-1, -1)
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if diff := cmp.Diff([]int{5, 6, 7}, r.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the older items beyond the capacity are evicted as Set does
+	expect := map[cache.EvictReason][]int{cache.EvictPurged: {-1}, cache.EvictCapacity: {0, 1, 2, 3, 4}}
+	if diff := cmp.Diff(expect, evicted); diff != "" {
+		t.Errorf("evicted mismatch (-want +got):\n%s", diff)
+	}
+	if s := r.Stats(); s.Evictions[cache.EvictCapacity] != 5 {
+		t.Errorf("expect 5 capacity evictions,got %v", s.Evictions)
+	}
+}
+
+func TestLRU_SnapshotJSON(t *testing.T) {
+	codec := WithCodec[string, []int](cache.JSONCodec[string]{}, cache.JSONCodec[[]int]{})
+	s := New[string, []int](32, codec)
+	s.Set("a", []int{1, 2})
+	s.Set("b", nil)
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("[1,2]")) {
+		t.Errorf("expect values encoded as JSON,got %q", buf.Bytes())
+	}
+	r := New[string, []int](32, codec)
+	if err := r.Restore(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	if v, ok := r.Get("a"); !ok || !cmp.Equal(v, []int{1, 2}) {
+		t.Errorf("expect [1 2],true;got %v,%v", v, ok)
+	}
+}
+
+func TestLRU_RestoreInvalid(t *testing.T) {
+	s := New[int, int](32)
+	s.Set(1, 1)
+	var buf bytes.Buffer
+	if err := s.Snapshot(&buf); err != nil {
+		t.Fatalf("expect nil,got %v", err)
+	}
+	data := buf.Bytes()
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-1],
+		"garbage":   []byte("not a snapshot"),
+	} {
+		if err := s.Restore(bytes.NewReader(data)); !errors.Is(err, cache.ErrInvalidSnapshot) {
+			t.Errorf("%s: expect ErrInvalidSnapshot,got %v", name, err)
+		}
+	}
+	if v, ok := s.Get(1); !ok || v != 1 {
+		t.Errorf("expect LRU unchanged,got %v,%v", v, ok)
+	}
+}
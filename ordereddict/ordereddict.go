@@ -0,0 +1,208 @@
+// Package ordereddict implement a thread safe map remembers the order keys were inserted, inspired by python OrderedDict
+// https://docs.python.org/3/library/collections.html#collections.OrderedDict
+package ordereddict
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/FelixSeptem/collections/internal/list"
+)
+
+// OrderedDict is a map iterates in the order keys were first inserted, the zero value isn't ready to use
+type OrderedDict[K comparable, V any] struct {
+	lock  sync.RWMutex
+	order *list.List[entry[K, V]]
+	items map[K]*list.Element[entry[K, V]]
+}
+
+// entry contains the key value pair order holds
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New return an empty OrderedDict
+func New[K comparable, V any]() *OrderedDict[K, V] {
+	return &OrderedDict[K, V]{
+		order: list.New[entry[K, V]](),
+		items: make(map[K]*list.Element[entry[K, V]]),
+	}
+}
+
+// Set the value of key, a new key is put at the end while an existing one keeps its position,
+// return if the key has existed before
+func (d *OrderedDict[K, V]) Set(key K, value V) (existed bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if e, ok := d.items[key]; ok {
+		e.Value.value = value
+		return true
+	}
+	d.items[key] = d.order.PushBack(entry[K, V]{key: key, value: value})
+	return false
+}
+
+// Get return the value of key and whether it exists
+func (d *OrderedDict[K, V]) Get(key K) (value V, ok bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	e, ok := d.items[key]
+	if !ok {
+		return value, false
+	}
+	return e.Value.value, true
+}
+
+// Contains check if the key exists
+func (d *OrderedDict[K, V]) Contains(key K) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	_, ok := d.items[key]
+	return ok
+}
+
+// Delete the key, return if it has existed before
+func (d *OrderedDict[K, V]) Delete(key K) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	e, ok := d.items[key]
+	if ok {
+		d.remove(e)
+	}
+	return ok
+}
+
+// MoveToEnd move an existing key to the end, or to the beginning if last is false, return false if key doesn't exist
+func (d *OrderedDict[K, V]) MoveToEnd(key K, last bool) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	e, ok := d.items[key]
+	if !ok {
+		return false
+	}
+	if last {
+		d.order.MoveToBack(e)
+	} else {
+		d.order.MoveToFront(e)
+	}
+	return true
+}
+
+// PopItem remove and return the last key value pair, or the first if last is false, ok is false if it's empty
+func (d *OrderedDict[K, V]) PopItem(last bool) (key K, value V, ok bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	e := d.order.Front()
+	if last {
+		e = d.order.Back()
+	}
+	if e == nil {
+		return key, value, false
+	}
+	d.remove(e)
+	return e.Value.key, e.Value.value, true
+}
+
+// return the number of keys
+func (d *OrderedDict[K, V]) Len() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.items)
+}
+
+// Keys return all keys in order
+func (d *OrderedDict[K, V]) Keys() []K {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	keys := make([]K, 0, len(d.items))
+	for e := d.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.key)
+	}
+	return keys
+}
+
+// Values return all values in the order of their keys
+func (d *OrderedDict[K, V]) Values() []V {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	values := make([]V, 0, len(d.items))
+	for e := d.order.Front(); e != nil; e = e.Next() {
+		values = append(values, e.Value.value)
+	}
+	return values
+}
+
+// All iterate over the key value pairs from the first to the last, the pairs are taken when the iteration starts
+// so it's safe to modify the dict in the loop
+func (d *OrderedDict[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, e := range d.snapshot() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the key value pairs from the last to the first, the pairs are taken when the iteration
+// starts so it's safe to modify the dict in the loop
+func (d *OrderedDict[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		entries := d.snapshot()
+		for i := len(entries) - 1; i >= 0; i-- {
+			if !yield(entries[i].key, entries[i].value) {
+				return
+			}
+		}
+	}
+}
+
+// Purge use to clear all keys
+func (d *OrderedDict[K, V]) Purge() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.order.Init()
+	clear(d.items)
+}
+
+// EqualFunc check if d and other hold the same keys in the same order with values equal by eq
+func (d *OrderedDict[K, V]) EqualFunc(other *OrderedDict[K, V], eq func(a, b V) bool) bool {
+	if d == other {
+		return true
+	}
+	a, b := d.snapshot(), other.snapshot()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].key != b[i].key || !eq(a[i].value, b[i].value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal check if a and b hold the same key value pairs in the same order
+func Equal[K comparable, V comparable](a, b *OrderedDict[K, V]) bool {
+	return a.EqualFunc(b, func(x, y V) bool {
+		return x == y
+	})
+}
+
+// return the key value pairs in order
+func (d *OrderedDict[K, V]) snapshot() []entry[K, V] {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	entries := make([]entry[K, V], 0, len(d.items))
+	for e := d.order.Front(); e != nil; e = e.Next() {
+		entries = append(entries, e.Value)
+	}
+	return entries
+}
+
+// remove the element from dict
+func (d *OrderedDict[K, V]) remove(e *list.Element[entry[K, V]]) {
+	d.order.Remove(e)
+	delete(d.items, e.Value.key)
+}
@@ -0,0 +1,133 @@
+package ordereddict
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOrderedDict_Set(t *testing.T) {
+	d := New[string, int]()
+	for i, k := range []string{"b", "a", "c"} {
+		if existed := d.Set(k, i); existed {
+			t.Errorf("expect false,got %v", existed)
+		}
+	}
+	if existed := d.Set("b", 10); !existed {
+		t.Errorf("expect true,got %v", existed)
+	}
+	if !cmp.Equal(d.Keys(), []string{"b", "a", "c"}) || !cmp.Equal(d.Values(), []int{10, 1, 2}) {
+		t.Errorf("expect [b a c] [10 1 2],got %v %v", d.Keys(), d.Values())
+	}
+	if v, ok := d.Get("b"); !ok || v != 10 {
+		t.Errorf("expect 10 with true,got %v with %v", v, ok)
+	}
+	if v, ok := d.Get("x"); ok || v != 0 {
+		t.Errorf("expect 0 with false,got %v with %v", v, ok)
+	}
+	if l := d.Len(); l != 3 {
+		t.Errorf("expect 3,got %d", l)
+	}
+}
+
+func TestOrderedDict_Delete(t *testing.T) {
+	d := New[string, int]()
+	d.Set("a", 1)
+	d.Set("b", 2)
+	if ok := d.Delete("a"); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := d.Delete("a"); ok || d.Contains("a") {
+		t.Errorf("expect false,got %v", ok)
+	}
+	d.Set("a", 1)
+	if !cmp.Equal(d.Keys(), []string{"b", "a"}) {
+		t.Errorf("expect [b a],got %v", d.Keys())
+	}
+	d.Purge()
+	if l := d.Len(); l != 0 || len(d.Keys()) != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+}
+
+func TestOrderedDict_MoveToEnd(t *testing.T) {
+	d := New[string, int]()
+	for i, k := range []string{"a", "b", "c", "d"} {
+		d.Set(k, i)
+	}
+	d.MoveToEnd("b", true)
+	d.MoveToEnd("c", false)
+	if ok := d.MoveToEnd("x", true); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if !cmp.Equal(d.Keys(), []string{"c", "a", "d", "b"}) {
+		t.Errorf("expect [c a d b],got %v", d.Keys())
+	}
+}
+
+func TestOrderedDict_PopItem(t *testing.T) {
+	d := New[string, int]()
+	for i, k := range []string{"a", "b", "c"} {
+		d.Set(k, i)
+	}
+	if k, v, ok := d.PopItem(true); !ok || k != "c" || v != 2 {
+		t.Errorf("expect c,2,true;got %v,%v,%v", k, v, ok)
+	}
+	if k, v, ok := d.PopItem(false); !ok || k != "a" || v != 0 {
+		t.Errorf("expect a,0,true;got %v,%v,%v", k, v, ok)
+	}
+	d.PopItem(false)
+	if k, v, ok := d.PopItem(true); ok || k != "" || v != 0 {
+		t.Errorf("expect empty,0,false;got %v,%v,%v", k, v, ok)
+	}
+}
+
+func TestOrderedDict_All(t *testing.T) {
+	d := New[int, string]()
+	for i, v := range []string{"a", "b", "c"} {
+		d.Set(i, v)
+	}
+	var forward, backward []string
+	for k, v := range d.All() {
+		forward = append(forward, v)
+		// modify the dict in the loop is safe
+		d.Delete(k)
+	}
+	for _, v := range New[int, string]().Backward() {
+		t.Errorf("expect no items,got %v", v)
+	}
+	for i, v := range []string{"a", "b", "c"} {
+		d.Set(i, v)
+	}
+	for _, v := range d.Backward() {
+		backward = append(backward, v)
+		if v == "b" {
+			break
+		}
+	}
+	if !cmp.Equal(forward, []string{"a", "b", "c"}) || !cmp.Equal(backward, []string{"c", "b"}) {
+		t.Errorf("expect [a b c] [c b],got %v %v", forward, backward)
+	}
+}
+
+func TestOrderedDict_Equal(t *testing.T) {
+	a, b := New[string, int](), New[string, int]()
+	a.Set("x", 1)
+	a.Set("y", 2)
+	b.Set("y", 2)
+	b.Set("x", 1)
+	if Equal(a, b) {
+		t.Errorf("expect false for a different order,got true")
+	}
+	b.MoveToEnd("y", true)
+	if !Equal(a, b) || !Equal(a, a) {
+		t.Errorf("expect true,got false")
+	}
+	b.Set("y", 3)
+	if Equal(a, b) {
+		t.Errorf("expect false for a different value,got true")
+	}
+	if !a.EqualFunc(b, func(x, y int) bool { return x/2 == y/2 }) {
+		t.Errorf("expect true,got false")
+	}
+}
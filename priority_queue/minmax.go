@@ -0,0 +1,115 @@
+package priority_queue
+
+import "math/bits"
+
+// the methods below keep items as a min-max heap, the item of a node on an even level is popped before all its
+// descendants and the one on an odd level after all of them, so both the item popped next and the one popped last
+// are found in O(1) and pushed, removed or fixed in O(log n)
+// ref:https://en.wikipedia.org/wiki/Min-max_heap
+
+// push an item into the min-max heap
+func (h *items[T, P]) push(v *Element[T, P]) {
+	h.Push(v)
+	h.up(len(h.items) - 1)
+}
+
+// remove and return the item at index i of the min-max heap
+func (h *items[T, P]) remove(i int) *Element[T, P] {
+	n := len(h.items) - 1
+	if i != n {
+		h.Swap(i, n)
+	}
+	v := h.Pop().(*Element[T, P])
+	if i != n {
+		h.fix(i)
+	}
+	return v
+}
+
+// fix re-establish the min-max heap after the item at index i has changed
+func (h *items[T, P]) fix(i int) {
+	v := h.items[i]
+	h.down(i)
+	// an item moving up is either a leaf or it has moved down already, so the items below it stay in order
+	h.up(v.index)
+}
+
+// return the index of the item would be popped last
+func (h *items[T, P]) worst() int {
+	switch len(h.items) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.Less(1, 2) {
+		return 2
+	}
+	return 1
+}
+
+// return if the item at index i is on a level ordered by Less
+func minLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+
+// return the order of the level of index i, the item of a node goes first by it among its descendants
+func (h *items[T, P]) order(i int) func(a, b int) bool {
+	if minLevel(i) {
+		return h.Less
+	}
+	return func(a, b int) bool {
+		return h.Less(b, a)
+	}
+}
+
+// move the item at index i up to its place
+func (h *items[T, P]) up(i int) {
+	if i == 0 {
+		return
+	}
+	// the parent is on a level of the opposite order
+	if p := (i - 1) / 2; h.order(i)(p, i) {
+		h.Swap(i, p)
+		i = p
+	}
+	less := h.order(i)
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !less(i, g) {
+			return
+		}
+		h.Swap(i, g)
+		i = g
+	}
+}
+
+// move the item at index i down to its place
+func (h *items[T, P]) down(i int) {
+	less := h.order(i)
+	n := len(h.items)
+	for {
+		// find the first among the children and grandchildren
+		m := 2*i + 1
+		if m >= n {
+			return
+		}
+		for _, j := range []int{2*i + 2, 4*i + 3, 4*i + 4, 4*i + 5, 4*i + 6} {
+			if j < n && less(j, m) {
+				m = j
+			}
+		}
+		if !less(m, i) {
+			return
+		}
+		h.Swap(m, i)
+		if m <= 2*i+2 {
+			return
+		}
+		// the parent of a grandchild is on a level of the opposite order
+		if p := (m - 1) / 2; less(p, m) {
+			h.Swap(m, p)
+		}
+		i = m
+	}
+}
@@ -0,0 +1,11 @@
+package priority_queue
+
+// Option configure the priority queue created by New, NewMin, NewMax and NewFunc
+type Option[T any, P any] func(*Queue[T, P])
+
+// WithEvictPolicy choose which item is discarded when pushing into a full queue, EvictWorst by default
+func WithEvictPolicy[T any, P any](policy EvictPolicy) Option[T, P] {
+	return func(pq *Queue[T, P]) {
+		pq.policy = policy
+	}
+}
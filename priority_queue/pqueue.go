@@ -1,8 +1,10 @@
-// Package priority_queue implement a thread safe priority powered by "container/heap"
+// Package priority_queue implement a thread safe priority queue powered by a min-max heap
 package priority_queue
 
 import (
-	"container/heap"
+	"cmp"
+	"iter"
+	"sort"
 	"sync"
 )
 
@@ -10,106 +12,264 @@ const (
 	Default_PQueue_Size = 1024
 )
 
-// PQueue implement a priority queue
-type PQueue struct {
+// EvictPolicy choose which item is discarded when pushing into a full queue
+type EvictPolicy int
+
+const (
+	// EvictWorst discard the item would be popped last, so the queue keeps the best items it has seen
+	EvictWorst EvictPolicy = iota
+	// EvictBest discard the item would be popped next
+	EvictBest
+)
+
+// Queue implement a fixed size priority queue holds values of type T ordered by priorities of type P,
+// items of equal priority are popped in the order they were pushed
+type Queue[T any, P any] struct {
 	lock     sync.RWMutex
 	capacity int
-	data     []*Payload
+	policy   EvictPolicy
+	data     items[T, P]
+	// seq is the insertion sequence of the next item pushed
+	seq uint64
 }
 
-// payload holds the data in priority queue
-type Payload struct {
-	Value    interface{}
-	Priority int
+// Element holds the data in priority queue
+type Element[T any, P any] struct {
+	Value    T
+	Priority P
 	index    int
+	seq      uint64
+}
+
+// PQueue implement a priority queue ordered by int priority, the highest first
+type PQueue[T any] = Queue[T, int]
+
+// Item holds the data in PQueue
+type Item[T any] = Element[T, int]
+
+// Payload is an Item holds arbitrary value, kept for the callers written before PQueue was type parameterized
+type Payload = Item[interface{}]
+
+// return a fix size priority queue holds arbitrary values
+func NewPQueue(size int) *PQueue[interface{}] {
+	return New[interface{}](size)
+}
+
+// New return a fix size priority queue holds values of type T, the highest priority is popped first
+func New[T any](size int, opts ...Option[T, int]) *PQueue[T] {
+	return NewMax[T, int](size, opts...)
+}
+
+// NewMax return a fix size priority queue pops the highest priority first
+func NewMax[T any, P cmp.Ordered](size int, opts ...Option[T, P]) *Queue[T, P] {
+	return NewFunc[T](size, func(a, b P) bool {
+		return a > b
+	}, opts...)
+}
+
+// NewMin return a fix size priority queue pops the lowest priority first
+func NewMin[T any, P cmp.Ordered](size int, opts ...Option[T, P]) *Queue[T, P] {
+	return NewFunc[T](size, cmp.Less[P], opts...)
 }
 
-// return a fix size priority queue
-func NewPQueue(size int) *PQueue {
+// NewFunc return a fix size priority queue pops a before b if less(a, b) is true, less must be a strict weak ordering
+func NewFunc[T any, P any](size int, less func(a, b P) bool, opts ...Option[T, P]) *Queue[T, P] {
 	if size <= 0 {
 		size = Default_PQueue_Size
 	}
-	pq := &PQueue{
+	pq := &Queue[T, P]{
 		capacity: size,
+		data:     items[T, P]{less: less},
+	}
+	for _, opt := range opts {
+		opt(pq)
 	}
-	heap.Init(pq)
 	return pq
 }
 
-// Push a item into priority queue
-func (pq *PQueue) PushItem(v *Payload) (evicted bool) {
+// Push a item into priority queue, if the queue is full an item is discarded by the eviction policy which may be v itself,
+// it takes O(log n) by either policy
+func (pq *Queue[T, P]) PushItem(v *Element[T, P]) (evicted bool) {
 	pq.lock.Lock()
 	defer pq.lock.Unlock()
-	heap.Push(pq, v)
-	if len(pq.data) > pq.capacity {
-		heap.Pop(pq)
-		return true
+	v.seq = pq.seq
+	pq.seq++
+	pq.data.push(v)
+	if len(pq.data.items) <= pq.capacity {
+		return false
+	}
+	if pq.policy == EvictBest {
+		pq.data.remove(0)
+	} else {
+		pq.data.remove(pq.data.worst())
 	}
-	return false
+	return true
 }
 
 // Pop a item from priority queue
-func (pq *PQueue) PopItem() (interface{}, bool) {
+func (pq *Queue[T, P]) PopItem() (*Element[T, P], bool) {
 	pq.lock.Lock()
 	defer pq.lock.Unlock()
-	if len(pq.data) == 0 {
+	if len(pq.data.items) == 0 {
+		return nil, false
+	}
+	return pq.data.remove(0), true
+}
+
+// Peek return the item would be popped next without removing it
+func (pq *Queue[T, P]) Peek() (*Element[T, P], bool) {
+	pq.lock.RLock()
+	defer pq.lock.RUnlock()
+	if len(pq.data.items) == 0 {
 		return nil, false
 	}
-	return heap.Pop(pq), true
+	return pq.data.items[0], true
+}
+
+// Update change the priority of an item in queue, return false if the item isn't in queue
+func (pq *Queue[T, P]) Update(v *Element[T, P], priority P) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	if !pq.contains(v) {
+		return false
+	}
+	v.Priority = priority
+	pq.data.fix(v.index)
+	return true
+}
+
+// RemoveItem remove an item from queue, return false if the item isn't in queue
+func (pq *Queue[T, P]) RemoveItem(v *Element[T, P]) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+	if !pq.contains(v) {
+		return false
+	}
+	pq.data.remove(v.index)
+	return true
+}
+
+// check if the item is in queue by the index it tracks
+func (pq *Queue[T, P]) contains(v *Element[T, P]) bool {
+	return v != nil && v.index >= 0 && v.index < len(pq.data.items) && pq.data.items[v.index] == v
 }
 
 // return queue size
-func (pq *PQueue) Cap() int {
+func (pq *Queue[T, P]) Cap() int {
 	return pq.capacity
 }
 
 // return queue size
-func (pq *PQueue) Length() int {
+func (pq *Queue[T, P]) Length() int {
 	pq.lock.RLock()
 	defer pq.lock.RUnlock()
-	return len(pq.data)
+	return len(pq.data.items)
 }
 
 // check if queue is empty
-func (pq *PQueue) IsEmpty() bool {
+func (pq *Queue[T, P]) IsEmpty() bool {
 	pq.lock.RLock()
 	defer pq.lock.RUnlock()
-	return len(pq.data) == 0
+	return len(pq.data.items) == 0
 }
 
 // check if queue if full(reach max capacity)
-func (pq *PQueue) IsFull() bool {
+func (pq *Queue[T, P]) IsFull() bool {
+	pq.lock.RLock()
+	defer pq.lock.RUnlock()
+	return len(pq.data.items) == pq.capacity
+}
+
+// All iterate over the positions and items in the order they would be popped without removing them,
+// the items are taken when the iteration starts so it's safe to modify the queue in the loop
+func (pq *Queue[T, P]) All() iter.Seq2[int, *Element[T, P]] {
+	return func(yield func(int, *Element[T, P]) bool) {
+		for i, item := range pq.snapshot() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the positions and items in the reverse order of All
+func (pq *Queue[T, P]) Backward() iter.Seq2[int, *Element[T, P]] {
+	return func(yield func(int, *Element[T, P]) bool) {
+		items := pq.snapshot()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(i, items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterate over the values in the order of All
+func (pq *Queue[T, P]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range pq.snapshot() {
+			if !yield(item.Value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order they would be popped
+func (pq *Queue[T, P]) snapshot() []*Element[T, P] {
 	pq.lock.RLock()
 	defer pq.lock.RUnlock()
-	return len(pq.data) == pq.capacity
+	items := make([]*Element[T, P], len(pq.data.items))
+	copy(items, pq.data.items)
+	sort.Slice(items, func(i, j int) bool {
+		return pq.data.before(items[i], items[j])
+	})
+	return items
 }
 
-// below is used to implement internal interface ref:https://godoc.org/container/heap shall not use it directly
-func (pq PQueue) Len() int {
-	return len(pq.data)
+// items implement the internal interface ref:https://godoc.org/container/heap
+// TopK keeps it as a binary heap by container/heap, Queue keeps it as a min-max heap by the methods in minmax.go
+type items[T any, P any] struct {
+	items []*Element[T, P]
+	less  func(a, b P) bool
 }
 
-func (pq PQueue) Less(i, j int) bool {
-	return pq.data[i].Priority > pq.data[j].Priority
+func (h *items[T, P]) Len() int {
+	return len(h.items)
 }
 
-func (pq PQueue) Swap(i, j int) {
-	pq.data[i], pq.data[j] = pq.data[j], pq.data[i]
-	pq.data[i].index, pq.data[j].index = i, j
+func (h *items[T, P]) Less(i, j int) bool {
+	return h.before(h.items[i], h.items[j])
 }
 
-func (pq *PQueue) Push(v interface{}) {
-	item := v.(*Payload)
-	item.index = len(pq.data)
-	pq.data = append(pq.data, item)
-	heap.Fix(pq, item.index)
+func (h *items[T, P]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index, h.items[j].index = i, j
 }
 
-func (pq *PQueue) Pop() interface{} {
-	old := *pq
-	n := len(old.data)
-	item := old.data[n-1]
+func (h *items[T, P]) Push(v interface{}) {
+	item := v.(*Element[T, P])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *items[T, P]) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
 	item.index = -1
-	pq.data = old.data[0 : n-1]
+	h.items = old[0 : n-1]
 	return item
 }
+
+// check if a is popped before b, the one pushed earlier goes first among equal priorities
+func (h *items[T, P]) before(a, b *Element[T, P]) bool {
+	if h.less(a.Priority, b.Priority) {
+		return true
+	}
+	if h.less(b.Priority, a.Priority) {
+		return false
+	}
+	return a.seq < b.seq
+}
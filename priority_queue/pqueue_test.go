@@ -1,8 +1,11 @@
 package priority_queue
 
 import (
-	"github.com/google/go-cmp/cmp"
+	"math/rand"
+	"slices"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestPQueue_Cap(t *testing.T) {
@@ -77,6 +80,243 @@ func TestPQueue_PopItem(t *testing.T) {
 	}
 }
 
+func TestPQueue_Typed(t *testing.T) {
+	pq := New[string](2)
+	pq.PushItem(&Item[string]{Value: "low", Priority: 1})
+	pq.PushItem(&Item[string]{Value: "high", Priority: 3})
+	v, ok := pq.PopItem()
+	if !ok || v.Value != "high" {
+		t.Errorf("expect high with true,got %+v with %v", v, ok)
+	}
+	if _, ok := pq.PopItem(); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if v, ok := pq.PopItem(); ok || v != nil {
+		t.Errorf("expect nil with false,got %+v with %v", v, ok)
+	}
+}
+
+func TestPQueue_Peek(t *testing.T) {
+	pq := New[string](8)
+	if v, ok := pq.Peek(); ok || v != nil {
+		t.Errorf("expect nil with false,got %+v with %v", v, ok)
+	}
+	pq.PushItem(&Item[string]{Value: "low", Priority: 1})
+	pq.PushItem(&Item[string]{Value: "high", Priority: 3})
+	if v, ok := pq.Peek(); !ok || v.Value != "high" {
+		t.Errorf("expect high with true,got %+v with %v", v, ok)
+	}
+	if l := pq.Length(); l != 2 {
+		t.Errorf("expect 2,got %d", l)
+	}
+}
+
+func TestPQueue_Update(t *testing.T) {
+	pq := New[string](8)
+	low := &Item[string]{Value: "low", Priority: 1}
+	mid := &Item[string]{Value: "mid", Priority: 2}
+	pq.PushItem(low)
+	pq.PushItem(mid)
+	pq.PushItem(&Item[string]{Value: "high", Priority: 3})
+	if ok := pq.Update(low, 4); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := pq.Update(mid, 0); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := pq.Update(&Item[string]{Value: "other"}, 5); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	var order []string
+	for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+		order = append(order, v.Value)
+	}
+	if !cmp.Equal(order, []string{"low", "high", "mid"}) {
+		t.Errorf("expect [low high mid],got %v", order)
+	}
+	if ok := pq.Update(low, 1); ok {
+		t.Errorf("expect false for a popped item,got %v", ok)
+	}
+}
+
+func TestPQueue_RemoveItem(t *testing.T) {
+	pq := New[int](8)
+	items := make([]*Item[int], 6)
+	for i := range items {
+		items[i] = &Item[int]{Value: i, Priority: i}
+		pq.PushItem(items[i])
+	}
+	if ok := pq.RemoveItem(items[5]); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := pq.RemoveItem(items[2]); !ok {
+		t.Errorf("expect true,got %v", ok)
+	}
+	if ok := pq.RemoveItem(items[2]); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	var order []int
+	for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+		order = append(order, v.Value)
+	}
+	if !cmp.Equal(order, []int{4, 3, 1, 0}) {
+		t.Errorf("expect [4 3 1 0],got %v", order)
+	}
+}
+
+func TestPQueue_NewMin(t *testing.T) {
+	pq := NewMin[string, float64](8)
+	for _, p := range []float64{2.5, 0.5, 1.5} {
+		pq.PushItem(&Element[string, float64]{Priority: p})
+	}
+	var order []float64
+	for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+		order = append(order, v.Priority)
+	}
+	if !cmp.Equal(order, []float64{0.5, 1.5, 2.5}) {
+		t.Errorf("expect [0.5 1.5 2.5],got %v", order)
+	}
+}
+
+func TestPQueue_NewFunc(t *testing.T) {
+	type deadline struct {
+		day, hour int
+	}
+	pq := NewFunc[string](8, func(a, b deadline) bool {
+		return a.day < b.day || a.day == b.day && a.hour < b.hour
+	})
+	pq.PushItem(&Element[string, deadline]{Value: "c", Priority: deadline{2, 1}})
+	pq.PushItem(&Element[string, deadline]{Value: "b", Priority: deadline{1, 9}})
+	pq.PushItem(&Element[string, deadline]{Value: "a", Priority: deadline{1, 3}})
+	var order []string
+	for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+		order = append(order, v.Value)
+	}
+	if !cmp.Equal(order, []string{"a", "b", "c"}) {
+		t.Errorf("expect [a b c],got %v", order)
+	}
+}
+
+func TestPQueue_FIFO(t *testing.T) {
+	pq := New[int](16)
+	for i := 0; i < 10; i++ {
+		pq.PushItem(&Item[int]{Value: i, Priority: i % 2})
+	}
+	var order []int
+	for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+		order = append(order, v.Value)
+	}
+	if !cmp.Equal(order, []int{1, 3, 5, 7, 9, 0, 2, 4, 6, 8}) {
+		t.Errorf("expect [1 3 5 7 9 0 2 4 6 8],got %v", order)
+	}
+}
+
+func TestPQueue_EvictPolicy(t *testing.T) {
+	tests := []struct {
+		policy EvictPolicy
+		expect []int
+	}{
+		{EvictWorst, []int{9, 8, 7}},
+		{EvictBest, []int{2, 1, 0}},
+	}
+	for _, tt := range tests {
+		pq := New[int](3, WithEvictPolicy[int, int](tt.policy))
+		var evictions int
+		for _, i := range []int{5, 0, 9, 1, 7, 2, 8} {
+			if pq.PushItem(&Item[int]{Value: i, Priority: i}) {
+				evictions++
+			}
+		}
+		if evictions != 4 {
+			t.Errorf("expect 4,got %d", evictions)
+		}
+		var order []int
+		for v, ok := pq.PopItem(); ok; v, ok = pq.PopItem() {
+			order = append(order, v.Value)
+		}
+		if !cmp.Equal(order, tt.expect) {
+			t.Errorf("expect %v,got %v", tt.expect, order)
+		}
+	}
+	// the latest pushed goes first among the worst of equal priority
+	pq := New[string](2)
+	pq.PushItem(&Item[string]{Value: "first", Priority: 1})
+	pq.PushItem(&Item[string]{Value: "second", Priority: 1})
+	pq.PushItem(&Item[string]{Value: "third", Priority: 1})
+	if v, _ := pq.PopItem(); v.Value != "first" {
+		t.Errorf("expect first,got %v", v.Value)
+	}
+	if v, _ := pq.PopItem(); v.Value != "second" {
+		t.Errorf("expect second,got %v", v.Value)
+	}
+}
+
+// order the items of a min queue by when they would be popped
+func byPriority(a, b *Element[int, int]) int {
+	if a.Priority != b.Priority {
+		return a.Priority - b.Priority
+	}
+	return int(a.seq) - int(b.seq)
+}
+
+func TestPQueue_MinMaxHeap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, policy := range []EvictPolicy{EvictWorst, EvictBest} {
+		pq := NewMin[int, int](32, WithEvictPolicy[int, int](policy))
+		// expect hold the items in the order they would be popped, the one pushed earlier first among equal priorities
+		var expect []*Element[int, int]
+		for i := 0; i < 10000; i++ {
+			switch op := r.Intn(10); {
+			case op < 5:
+				v := &Element[int, int]{Value: i, Priority: r.Intn(20)}
+				full := len(expect) == 32
+				if evicted := pq.PushItem(v); evicted != full {
+					t.Fatalf("expect evicted %v,got %v", full, evicted)
+				}
+				expect = append(expect, v)
+				slices.SortFunc(expect, byPriority)
+				if policy == EvictBest && full {
+					expect = expect[1:]
+				} else if full {
+					expect = expect[:32]
+				}
+			case op < 7:
+				v, ok := pq.PopItem()
+				if ok != (len(expect) > 0) || ok && v != expect[0] {
+					t.Fatalf("expect %v,got %v with %v", expect, v, ok)
+				}
+				if ok {
+					expect = expect[1:]
+				}
+			case op < 9 && len(expect) > 0:
+				// an updated item keeps its position among the items of equal priority by when it was pushed
+				v := expect[r.Intn(len(expect))]
+				pq.Update(v, r.Intn(20))
+				slices.SortFunc(expect, byPriority)
+			case len(expect) > 0:
+				j := r.Intn(len(expect))
+				pq.RemoveItem(expect[j])
+				expect = slices.Delete(expect, j, j+1)
+			}
+			h := &pq.data
+			for j := 1; j < len(h.items); j++ {
+				// every item goes after its ancestors on the levels ordered by Less and before the ones on the others
+				for a := (j - 1) / 2; ; a = (a - 1) / 2 {
+					if minLevel(a) == h.Less(j, a) {
+						t.Fatalf("expect min-max heap order between %d and its ancestor %d", j, a)
+					}
+					if a == 0 {
+						break
+					}
+				}
+			}
+			if len(expect) > 0 && h.items[h.worst()] != expect[len(expect)-1] {
+				t.Fatalf("expect worst %v,got %v", expect[len(expect)-1], h.items[h.worst()])
+			}
+		}
+	}
+}
+
 func BenchmarkPQueue_PushItem(b *testing.B) {
 	b.StopTimer()
 	pq := NewPQueue(8096)
@@ -106,3 +346,35 @@ func BenchmarkPQueue_PopItem(b *testing.B) {
 		pq.PopItem()
 	}
 }
+
+func TestPQueue_All(t *testing.T) {
+	pq := New[string](8)
+	for i, v := range []string{"b", "c", "a", "d"} {
+		pq.PushItem(&Item[string]{Value: v, Priority: []int{2, 1, 3, 1}[i]})
+	}
+	var order []string
+	for i, v := range pq.All() {
+		if i != len(order) {
+			t.Errorf("expect %d,got %d", len(order), i)
+		}
+		order = append(order, v.Value)
+		// modify the queue in the loop is safe
+		pq.RemoveItem(v)
+	}
+	if !cmp.Equal(order, []string{"a", "b", "c", "d"}) || pq.Length() != 0 {
+		t.Errorf("expect [a b c d] drained,got %v with %d left", order, pq.Length())
+	}
+	for i, v := range []string{"b", "c", "a"} {
+		pq.PushItem(&Item[string]{Value: v, Priority: []int{2, 1, 3}[i]})
+	}
+	var backward []string
+	for _, v := range pq.Backward() {
+		backward = append(backward, v.Value)
+	}
+	if values := slices.Collect(pq.Values()); !cmp.Equal(values, []string{"a", "b", "c"}) || !cmp.Equal(backward, []string{"c", "b", "a"}) {
+		t.Errorf("expect [a b c] [c b a],got %v %v", values, backward)
+	}
+	if l := pq.Length(); l != 3 {
+		t.Errorf("expect iterating doesn't drain,got %d", l)
+	}
+}
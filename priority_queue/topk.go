@@ -0,0 +1,102 @@
+package priority_queue
+
+import (
+	"cmp"
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// TopK keeps the k best values offered to it by score, it's safe for concurrent use
+type TopK[T any, S any] struct {
+	lock sync.Mutex
+	k    int
+	// data is a heap holds the worst value kept on top, so it's the one compared with and replaced by a better offer
+	data items[T, S]
+	// seq counts down so among equal scores the value offered earlier is the better one
+	seq uint64
+}
+
+// NewTopK return a TopK keeps the k values of the highest scores
+func NewTopK[T any, S cmp.Ordered](k int) *TopK[T, S] {
+	return NewTopKFunc[T](k, func(a, b S) bool {
+		return a > b
+	})
+}
+
+// NewTopKFunc return a TopK keeps the k best values, a score is better than b if better(a, b) is true,
+// better must be a strict weak ordering
+func NewTopKFunc[T any, S any](k int, better func(a, b S) bool) *TopK[T, S] {
+	if k <= 0 {
+		k = Default_PQueue_Size
+	}
+	return &TopK[T, S]{
+		k: k,
+		data: items[T, S]{less: func(a, b S) bool {
+			return better(b, a)
+		}},
+		seq: ^uint64(0),
+	}
+}
+
+// Offer a value with its score, return if it's kept, a value of the same score as the worst kept one is dropped
+// when TopK is full
+func (t *TopK[T, S]) Offer(value T, score S) (kept bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	v := &Element[T, S]{Value: value, Priority: score, seq: t.seq}
+	t.seq--
+	if len(t.data.items) < t.k {
+		heap.Push(&t.data, v)
+		return true
+	}
+	worst := t.data.items[0]
+	if !t.data.less(worst.Priority, score) {
+		return false
+	}
+	worst.index = -1
+	v.index = 0
+	t.data.items[0] = v
+	heap.Fix(&t.data, 0)
+	return true
+}
+
+// Snapshot return the values kept from the best to the worst
+func (t *TopK[T, S]) Snapshot() []Element[T, S] {
+	t.lock.Lock()
+	sorted := make([]*Element[T, S], len(t.data.items))
+	copy(sorted, t.data.items)
+	t.lock.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return t.data.before(sorted[j], sorted[i])
+	})
+	snapshot := make([]Element[T, S], len(sorted))
+	for i, v := range sorted {
+		snapshot[i] = Element[T, S]{Value: v.Value, Priority: v.Priority}
+	}
+	return snapshot
+}
+
+// Merge offer the values kept by other, so TopKs filled by different goroutines or shards can be combined,
+// other is left unchanged
+func (t *TopK[T, S]) Merge(other *TopK[T, S]) {
+	if other == t {
+		return
+	}
+	for _, v := range other.Snapshot() {
+		t.Offer(v.Value, v.Priority)
+	}
+}
+
+// return the number of values kept
+func (t *TopK[T, S]) Len() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.data.items)
+}
+
+// return the max number of values kept
+func (t *TopK[T, S]) K() int {
+	return t.k
+}
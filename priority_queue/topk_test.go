@@ -0,0 +1,99 @@
+package priority_queue
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// return the values of snapshot
+func values[T any, S any](snapshot []Element[T, S]) []T {
+	values := make([]T, len(snapshot))
+	for i, v := range snapshot {
+		values[i] = v.Value
+	}
+	return values
+}
+
+func TestTopK_Offer(t *testing.T) {
+	top := NewTopK[string, int](3)
+	if v := top.K(); v != 3 {
+		t.Errorf("expect 3,got %d", v)
+	}
+	for i, v := range []string{"a", "b", "c", "d", "e", "f"} {
+		top.Offer(v, []int{5, 1, 7, 3, 9, 2}[i])
+	}
+	if l := top.Len(); l != 3 {
+		t.Errorf("expect 3,got %d", l)
+	}
+	if kept := top.Offer("g", 5); kept {
+		t.Errorf("expect false for a tie with the worst,got %v", kept)
+	}
+	if kept := top.Offer("h", 6); !kept {
+		t.Errorf("expect true,got %v", kept)
+	}
+	snapshot := top.Snapshot()
+	if !cmp.Equal(values(snapshot), []string{"e", "c", "h"}) {
+		t.Errorf("expect [e c h],got %v", values(snapshot))
+	}
+	if snapshot[0].Priority != 9 {
+		t.Errorf("expect 9,got %d", snapshot[0].Priority)
+	}
+}
+
+func TestTopK_Ties(t *testing.T) {
+	top := NewTopK[int, int](3)
+	for i := 0; i < 6; i++ {
+		top.Offer(i, 1)
+	}
+	if v := values(top.Snapshot()); !cmp.Equal(v, []int{0, 1, 2}) {
+		t.Errorf("expect [0 1 2],got %v", v)
+	}
+}
+
+func TestTopK_NewTopKFunc(t *testing.T) {
+	// keep the shortest
+	top := NewTopKFunc[string](2, func(a, b int) bool {
+		return a < b
+	})
+	for _, v := range []string{"ccc", "a", "dddd", "bb"} {
+		top.Offer(v, len(v))
+	}
+	if v := values(top.Snapshot()); !cmp.Equal(v, []string{"a", "bb"}) {
+		t.Errorf("expect [a bb],got %v", v)
+	}
+}
+
+func TestTopK_Merge(t *testing.T) {
+	const k, shards = 10, 4
+	r := rand.New(rand.NewSource(1))
+	scores := r.Perm(1000)
+	tops := make([]*TopK[int, int], shards)
+	var wg sync.WaitGroup
+	for i := range tops {
+		tops[i] = NewTopK[int, int](k)
+		wg.Add(1)
+		go func(top *TopK[int, int], scores []int) {
+			defer wg.Done()
+			for _, s := range scores {
+				top.Offer(s, s)
+			}
+		}(tops[i], scores[i*len(scores)/shards:(i+1)*len(scores)/shards])
+	}
+	wg.Wait()
+	for _, top := range tops[1:] {
+		tops[0].Merge(top)
+	}
+	tops[0].Merge(tops[0])
+
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+	if v := values(tops[0].Snapshot()); !cmp.Equal(v, scores[:k]) {
+		t.Errorf("expect %v,got %v", scores[:k], v)
+	}
+	if l := tops[1].Len(); l != k {
+		t.Errorf("expect %d,got %d", k, l)
+	}
+}
@@ -0,0 +1,223 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/FelixSeptem/collections/internal/list"
+)
+
+var (
+	// ErrClosed is returned by putting into a closed Blocking queue or taking from a closed and drained one
+	ErrClosed = errors.New("queue: closed")
+	// ErrTimeout is returned by TryPut and TryTake when the timeout passed
+	ErrTimeout = errors.New("queue: timeout")
+)
+
+// a closed channel stands for a timeout already passed
+var expired = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// Blocking is a fixed size FIFO queue shared by producers and consumers, Put waits for space instead of evicting
+// the oldest item and Take waits for an item instead of returning the zero value
+type Blocking[T any] struct {
+	lock     sync.Mutex
+	capacity int
+	items    *list.List[T]
+	closed   bool
+	// notEmpty and notFull are closed to wake up all the waiters then replaced
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+// NewBlocking return a given size blocking queue holds items of type T
+func NewBlocking[T any](size int) *Blocking[T] {
+	if size <= 0 {
+		size = Default_Queue_Size
+	}
+	return &Blocking[T]{
+		capacity: size,
+		items:    list.New[T](),
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+}
+
+// Put add item at the tail of queue, it waits for space until ctx is done
+func (q *Blocking[T]) Put(ctx context.Context, item T) error {
+	if err := q.put(item, ctx.Done()); err != ErrTimeout {
+		return err
+	}
+	return ctx.Err()
+}
+
+// TryPut add item at the tail of queue, it waits for space at most timeout, a non-positive timeout means don't wait
+func (q *Blocking[T]) TryPut(item T, timeout time.Duration) error {
+	done, stop := after(timeout)
+	defer stop()
+	return q.put(item, done)
+}
+
+// Take remove and return the item at the head of queue, it waits for an item until ctx is done
+func (q *Blocking[T]) Take(ctx context.Context) (T, error) {
+	item, err := q.take(ctx.Done())
+	if err != ErrTimeout {
+		return item, err
+	}
+	return item, ctx.Err()
+}
+
+// TryTake remove and return the item at the head of queue, it waits for an item at most timeout,
+// a non-positive timeout means don't wait
+func (q *Blocking[T]) TryTake(timeout time.Duration) (T, error) {
+	done, stop := after(timeout)
+	defer stop()
+	return q.take(done)
+}
+
+// Close reject further Put and wake up all the waiters, the items left can still be taken, it's safe to call more than once
+func (q *Blocking[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	broadcast(&q.notEmpty)
+	broadcast(&q.notFull)
+}
+
+// return the queue length
+func (q *Blocking[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.items.Len()
+}
+
+// return the queue capacity
+func (q *Blocking[T]) Cap() int {
+	return q.capacity
+}
+
+// put item into queue unless it's closed, wait for space until done is closed
+func (q *Blocking[T]) put(item T, done <-chan struct{}) error {
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return ErrClosed
+		}
+		if q.items.Len() < q.capacity {
+			// takers wait only while queue is empty
+			if q.items.PushBack(item); q.items.Len() == 1 {
+				broadcast(&q.notEmpty)
+			}
+			q.lock.Unlock()
+			return nil
+		}
+		notFull := q.notFull
+		q.lock.Unlock()
+
+		select {
+		case <-notFull:
+		case <-done:
+			return ErrTimeout
+		}
+	}
+}
+
+// take item from queue unless it's closed and drained, wait for an item until done is closed
+func (q *Blocking[T]) take(done <-chan struct{}) (item T, err error) {
+	for {
+		q.lock.Lock()
+		if q.items.Len() > 0 {
+			// putters wait only while queue is full
+			if q.items.Len() == q.capacity {
+				broadcast(&q.notFull)
+			}
+			item = q.items.Remove(q.items.Front())
+			q.lock.Unlock()
+			return item, nil
+		}
+		if q.closed {
+			q.lock.Unlock()
+			return item, ErrClosed
+		}
+		notEmpty := q.notEmpty
+		q.lock.Unlock()
+
+		select {
+		case <-notEmpty:
+		case <-done:
+			return item, ErrTimeout
+		}
+	}
+}
+
+// wake up the waiters of c by closing it, then replace it for the next ones
+func broadcast(c *chan struct{}) {
+	close(*c)
+	*c = make(chan struct{})
+}
+
+// return a channel closed after timeout and a function release its timer
+func after(timeout time.Duration) (<-chan struct{}, func()) {
+	if timeout <= 0 {
+		return expired, func() {}
+	}
+	done := make(chan struct{})
+	t := time.AfterFunc(timeout, func() { close(done) })
+	return done, func() { t.Stop() }
+}
+
+// All iterate over the positions and items from the head to the tail, the items are taken when the iteration starts
+// so it's safe to modify the queue in the loop
+func (q *Blocking[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, item := range q.snapshot() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the positions and items in the reverse order of All
+func (q *Blocking[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		items := q.snapshot()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(i, items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterate over the items in the order of All
+func (q *Blocking[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range q.snapshot() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// return the items from the head to the tail
+func (q *Blocking[T]) snapshot() []T {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	items := make([]T, 0, q.items.Len())
+	for e := q.items.Front(); e != nil; e = e.Next() {
+		items = append(items, e.Value)
+	}
+	return items
+}
@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlocking_PutTake(t *testing.T) {
+	ctx := context.Background()
+	q := NewBlocking[int](2)
+	if v := q.Cap(); v != 2 {
+		t.Errorf("expect 2,got %d", v)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := q.Put(ctx, i); err != nil {
+			t.Errorf("expect nil,got %v", err)
+		}
+	}
+	if l := q.Len(); l != 2 {
+		t.Errorf("expect 2,got %d", l)
+	}
+	for i := 1; i <= 2; i++ {
+		if v, err := q.Take(ctx); err != nil || v != i {
+			t.Errorf("expect %d with nil,got %v with %v", i, v, err)
+		}
+	}
+}
+
+func TestBlocking_Wait(t *testing.T) {
+	ctx := context.Background()
+	q := NewBlocking[int](1)
+	const n = 100
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if err := q.Put(ctx, i); err != nil {
+				t.Errorf("expect nil,got %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if v, err := q.Take(ctx); err != nil || v != i {
+				t.Errorf("expect %d with nil,got %v with %v", i, v, err)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestBlocking_Cancel(t *testing.T) {
+	q := NewBlocking[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Take(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect %v,got %v", context.DeadlineExceeded, err)
+	}
+	q.Put(context.Background(), 1)
+	if err := q.Put(ctx, 2); err != context.DeadlineExceeded {
+		t.Errorf("expect %v,got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestBlocking_Try(t *testing.T) {
+	q := NewBlocking[int](1)
+	if _, err := q.TryTake(0); err != ErrTimeout {
+		t.Errorf("expect %v,got %v", ErrTimeout, err)
+	}
+	if err := q.TryPut(1, 0); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	if err := q.TryPut(2, 10*time.Millisecond); err != ErrTimeout {
+		t.Errorf("expect %v,got %v", ErrTimeout, err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.TryTake(0)
+	}()
+	if err := q.TryPut(2, time.Minute); err != nil {
+		t.Errorf("expect nil,got %v", err)
+	}
+	if v, err := q.TryTake(time.Minute); err != nil || v != 2 {
+		t.Errorf("expect 2 with nil,got %v with %v", v, err)
+	}
+}
+
+func TestBlocking_Close(t *testing.T) {
+	ctx := context.Background()
+	q := NewBlocking[int](1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// the taker either gets the item or is woken up by Close
+		if v, err := q.Take(ctx); err != ErrClosed && v != 1 {
+			t.Errorf("expect 1 or %v,got %v with %v", ErrClosed, v, err)
+		}
+	}()
+	q.Put(ctx, 1)
+	go func() {
+		defer wg.Done()
+		if err := q.Put(ctx, 2); err != ErrClosed && err != nil {
+			t.Errorf("expect nil or %v,got %v", ErrClosed, err)
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	q.Close()
+	wg.Wait()
+	if err := q.Put(ctx, 3); err != ErrClosed {
+		t.Errorf("expect %v,got %v", ErrClosed, err)
+	}
+	// the items left can still be taken
+	for q.Len() > 0 {
+		if _, err := q.Take(ctx); err != nil {
+			t.Errorf("expect nil,got %v", err)
+		}
+	}
+	if _, err := q.Take(ctx); err != ErrClosed {
+		t.Errorf("expect %v,got %v", ErrClosed, err)
+	}
+}
+
+func TestBlocking_All(t *testing.T) {
+	q := NewBlocking[int](8)
+	for i := 1; i <= 3; i++ {
+		q.TryPut(i, 0)
+	}
+	var items []int
+	for _, v := range q.All() {
+		items = append(items, v)
+		q.TryTake(0)
+	}
+	if len(items) != 3 || items[0] != 1 || items[2] != 3 || q.Len() != 0 {
+		t.Errorf("expect [1 2 3] taken,got %v with %d left", items, q.Len())
+	}
+}
@@ -2,8 +2,10 @@
 package queue
 
 import (
-	"container/list"
+	"iter"
 	"sync"
+
+	"github.com/FelixSeptem/collections/internal/list"
 )
 
 const (
@@ -11,25 +13,30 @@ const (
 )
 
 // a fixed size FILO queue
-type Queue struct {
+type Queue[T any] struct {
 	lock     sync.RWMutex
 	capacity int
-	items    *list.List
+	items    *list.List[T]
+}
+
+// NewQueue return a given size queue holds arbitrary items
+func NewQueue(size int) *Queue[interface{}] {
+	return New[interface{}](size)
 }
 
-// NewQueue return a given size queue
-func NewQueue(size int) *Queue {
+// New return a given size queue holds items of type T
+func New[T any](size int) *Queue[T] {
 	if size <= 0 {
 		size = Default_Queue_Size
 	}
-	return &Queue{
-		items:    list.New(),
+	return &Queue[T]{
+		items:    list.New[T](),
 		capacity: size,
 	}
 }
 
 // Push a new item into queue
-func (q *Queue) Push(item interface{}) (evicted bool) {
+func (q *Queue[T]) Push(item T) (evicted bool) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	q.items.PushBack(item)
@@ -41,57 +48,103 @@ func (q *Queue) Push(item interface{}) (evicted bool) {
 }
 
 // Pop a item from queue
-func (q *Queue) Pop() interface{} {
+func (q *Queue[T]) Pop() (item T) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	if q.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return q.items.Remove(q.items.Front())
 }
 
 // return the queue length
-func (q *Queue) Len() int {
+func (q *Queue[T]) Len() int {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
 	return q.items.Len()
 }
 
 // return the queue capacity
-func (q *Queue) Cap() int {
+func (q *Queue[T]) Cap() int {
 	return q.capacity
 }
 
 // Get the item at the head of queue but don't remove it from queue
-func (q *Queue) GetHead() interface{} {
+func (q *Queue[T]) GetHead() (item T) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	if q.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return q.items.Front().Value
 }
 
 // Get the item at the tail of queue
-func (q *Queue) GetTail() interface{} {
+func (q *Queue[T]) GetTail() (item T) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	if q.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return q.items.Back().Value
 }
 
 // Check if the queue is empty
-func (q *Queue) IsEmpty() bool {
+func (q *Queue[T]) IsEmpty() bool {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	return q.items.Len() == 0
 }
 
 // Check if the queue is full
-func (q *Queue) IsFull() bool {
+func (q *Queue[T]) IsFull() bool {
 	q.lock.RLock()
 	defer q.lock.RUnlock()
 	return q.items.Len() == q.capacity
 }
+
+// All iterate over the positions and items from the head to the tail, the items are taken when the iteration starts
+// so it's safe to modify the queue in the loop
+func (q *Queue[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, item := range q.snapshot() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the positions and items in the reverse order of All
+func (q *Queue[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		items := q.snapshot()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(i, items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterate over the items in the order of All
+func (q *Queue[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range q.snapshot() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// return the items from the head to the tail
+func (q *Queue[T]) snapshot() []T {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	items := make([]T, 0, q.items.Len())
+	for e := q.items.Front(); e != nil; e = e.Next() {
+		items = append(items, e.Value)
+	}
+	return items
+}
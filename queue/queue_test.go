@@ -1,6 +1,11 @@
 package queue
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestQueue_Cap(t *testing.T) {
 	q := NewQueue(32)
@@ -88,6 +93,19 @@ func TestQueue_Pop(t *testing.T) {
 	}
 }
 
+func TestQueue_Typed(t *testing.T) {
+	q := New[string](2)
+	if v := q.Pop(); v != "" {
+		t.Errorf("expect empty string,got %q", v)
+	}
+	q.Push("a")
+	q.Push("b")
+	q.Push("c")
+	if v := q.GetHead(); v != "b" {
+		t.Errorf("expect b,got %q", v)
+	}
+}
+
 func BenchmarkQueue_Push(b *testing.B) {
 	b.StopTimer()
 	q := NewQueue(8096)
@@ -108,3 +126,34 @@ func BenchmarkQueue_Pop(b *testing.B) {
 		q.Pop()
 	}
 }
+
+func TestQueue_All(t *testing.T) {
+	q := New[int](8)
+	for i := 1; i <= 3; i++ {
+		q.Push(i)
+	}
+	var positions []int
+	var items []int
+	for i, v := range q.All() {
+		positions = append(positions, i)
+		items = append(items, v)
+		// modify the queue in the loop is safe
+		q.Pop()
+	}
+	if !cmp.Equal(positions, []int{0, 1, 2}) || !cmp.Equal(items, []int{1, 2, 3}) {
+		t.Errorf("expect [0 1 2] [1 2 3],got %v %v", positions, items)
+	}
+	for i := 1; i <= 3; i++ {
+		q.Push(i)
+	}
+	var backward []int
+	for i, v := range q.Backward() {
+		if i == 0 {
+			break
+		}
+		backward = append(backward, v)
+	}
+	if values := slices.Collect(q.Values()); !cmp.Equal(values, []int{1, 2, 3}) || !cmp.Equal(backward, []int{3, 2}) {
+		t.Errorf("expect [1 2 3] [3 2],got %v %v", values, backward)
+	}
+}
@@ -0,0 +1,21 @@
+package s3fifo
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the S3-FIFO created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the S3-FIFO except the ones returned by PopOldest,
+// an item evicted from the small queue is reported with cache.EvictCapacity though its key is kept by the ghost queue
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.evicted.SetCallback(fn)
+	}
+}
+
+// WithSmallRatio set the share of capacity taken by the small queue, Default_Small_Ratio by default
+func WithSmallRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.smallRatio = ratio
+	}
+}
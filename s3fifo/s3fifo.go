@@ -0,0 +1,375 @@
+// Package s3fifo implement S3-FIFO as described in "FIFO queues are all you need for cache eviction" by Juncheng Yang,
+// Yazhuo Zhang, Ziyue Qiu, Yao Yue and K. V. Rashmi https://dl.acm.org/doi/10.1145/3600006.3613147
+// new items enter a small FIFO queue and only the ones hit there move to the main FIFO queue, the keys evicted from
+// the small queue are remembered by a ghost queue so they enter the main queue directly when set again,
+// a hit only increments a small counter of the item so Get takes the read lock only
+package s3fifo
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default S3-FIFO size
+	Default_S3FIFO_Size = 1024
+	// default share of capacity taken by the small queue
+	Default_Small_Ratio = 0.1
+	// hits counted per item saturate at maxFreq as the 2 bit counters of the paper
+	maxFreq = 3
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// Cache implements a thread safe fixed size S3-FIFO cache
+type Cache[K comparable, V any] struct {
+	lock       sync.RWMutex
+	capacity   int
+	smallSize  int
+	mainSize   int
+	smallRatio float64
+
+	// small and main hold items from the newest at front to the oldest at back
+	small *list.List[payload[K, V]]
+	main  *list.List[payload[K, V]]
+	items map[K]*list.Element[payload[K, V]]
+	// ghost remember as many keys evicted from small as main holds, from the newest at front
+	ghost  *list.List[K]
+	ghosts map[K]*list.Element[K]
+
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+}
+
+// payload contains the value small and main hold
+type payload[K comparable, V any] struct {
+	key   K
+	value V
+	// freq is incremented by hits holding the read lock only so it's accessed atomically
+	freq uint32
+	// main tell if the item is in main
+	main bool
+}
+
+// New return a given size S3-FIFO holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_S3FIFO_Size
+	}
+	c := &Cache[K, V]{
+		capacity:   size,
+		smallRatio: Default_Small_Ratio,
+		small:      list.New[payload[K, V]](),
+		main:       list.New[payload[K, V]](),
+		items:      make(map[K]*list.Element[payload[K, V]]),
+		ghost:      list.New[K](),
+		ghosts:     make(map[K]*list.Element[K]),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.smallSize = min(max(1, int(float64(size)*c.smallRatio)), size)
+	c.mainSize = size - c.smallSize
+	return c
+}
+
+// return the S3-FIFO running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the S3-FIFO statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the S3-FIFO statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the S3-FIFO max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into S3-FIFO, into main if the ghost queue remembers the key otherwise into small
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	// key has exists, update it to new value
+	if v, ok := c.items[key]; ok {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		touch(v)
+		return false
+	}
+	if len(c.items) >= c.capacity {
+		c.reclaim()
+		evicted = true
+	}
+	if g, ok := c.ghosts[key]; ok {
+		c.removeGhost(g)
+		c.items[key] = c.main.PushFront(payload[K, V]{key: key, value: value, main: true})
+	} else {
+		c.items[key] = c.small.PushFront(payload[K, V]{key: key, value: value})
+	}
+	return evicted
+}
+
+// Get value from S3-FIFO by key, only the read lock is taken
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.get(key)
+}
+
+// get shall be called with the read or write lock held
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+	v, ok := c.items[key]
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	touch(v)
+	c.stats.Hit()
+	return v.Value.value, ok
+}
+
+// Cotains check if the S3-FIFO contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	v, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
+}
+
+// Remove the item from cache by key, a key only remembered by the ghost queue is forgotten but reported as not existed
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.items[key]; ok {
+		c.evict(v, cache.EvictRemoved)
+		return true
+	}
+	if g, ok := c.ghosts[key]; ok {
+		c.removeGhost(g)
+	}
+	return false
+}
+
+// Remove and return the oldest item of the queue S3-FIFO would evict from next
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the S3-FIFO is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		if v := l.Back(); v != nil {
+			c.removeItem(v)
+			return v.Value.key, v.Value.value, true
+		}
+	}
+	return key, value, false
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into S3-FIFO
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the S3-FIFO hold, the ones of the queue evicted from next first, each queue from the oldest
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			keys = append(keys, v.Value.key)
+		}
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without counting hits, the items are
+// taken when the iteration starts so it's safe to modify the S3-FIFO in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			// copy the key and value only since freq may be written concurrently
+			items = append(items, payload[K, V]{key: v.Value.key, value: v.Value.value})
+		}
+	}
+	return items
+}
+
+// return the S3-FIFO length
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items)
+}
+
+// Purge use to clear all items and the keys the ghost queue remembers in S3-FIFO, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			c.stats.Evict(cache.EvictPurged)
+			c.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+		}
+		l.Init()
+	}
+	c.ghost.Init()
+	clear(c.items)
+	clear(c.ghosts)
+}
+
+// return small and main, the one reclaim evicts from first
+func (c *Cache[K, V]) lists() []*list.List[payload[K, V]] {
+	if c.small.Len() >= c.smallSize || c.main.Len() == 0 {
+		return []*list.List[payload[K, V]]{c.small, c.main}
+	}
+	return []*list.List[payload[K, V]]{c.main, c.small}
+}
+
+// touch count a hit of item, the counter saturates at maxFreq
+func touch[K comparable, V any](e *list.Element[payload[K, V]]) {
+	for {
+		f := atomic.LoadUint32(&e.Value.freq)
+		if f >= maxFreq || atomic.CompareAndSwapUint32(&e.Value.freq, f, f+1) {
+			return
+		}
+	}
+}
+
+// reclaim evict an item to make room for a new one, from small if it has reached its size otherwise from main
+func (c *Cache[K, V]) reclaim() {
+	if c.small.Len() >= c.smallSize || c.main.Len() == 0 {
+		if c.reclaimSmall() {
+			return
+		}
+	}
+	c.reclaimMain()
+}
+
+// reclaimSmall move the oldest items of small hit more than once to main until one hit at most is found, which is
+// evicted and remembered by the ghost queue, if main overflows on the way its item is evicted instead,
+// return false if small runs out without evicting any item
+func (c *Cache[K, V]) reclaimSmall() bool {
+	for v := c.small.Back(); v != nil; v = c.small.Back() {
+		if atomic.LoadUint32(&v.Value.freq) > 1 {
+			v.Value.main = true
+			c.main.PushFrontElement(v)
+			if c.main.Len() > c.mainSize {
+				c.reclaimMain()
+				return true
+			}
+			continue
+		}
+		c.evict(v, cache.EvictCapacity)
+		c.ghosts[v.Value.key] = c.ghost.PushFront(v.Value.key)
+		if c.ghost.Len() > c.mainSize {
+			c.removeGhost(c.ghost.Back())
+		}
+		return true
+	}
+	return false
+}
+
+// reclaimMain evict the oldest item of main which isn't hit since it's inserted or reinserted, the items hit on the
+// way are reinserted with their counters decremented, main shall not be empty
+func (c *Cache[K, V]) reclaimMain() {
+	for v := c.main.Back(); ; v = c.main.Back() {
+		if f := atomic.LoadUint32(&v.Value.freq); f > 0 {
+			atomic.StoreUint32(&v.Value.freq, f-1)
+			c.main.MoveToFront(v)
+			continue
+		}
+		c.evict(v, cache.EvictCapacity)
+		return
+	}
+}
+
+// remove item from S3-FIFO and notify the eviction callback
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.removeItem(e)
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from small or main
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	if e.Value.main {
+		c.main.Remove(e)
+	} else {
+		c.small.Remove(e)
+	}
+	delete(c.items, e.Value.key)
+}
+
+// remove key from the ghost queue
+func (c *Cache[K, V]) removeGhost(e *list.Element[K]) {
+	c.ghost.Remove(e)
+	delete(c.ghosts, e.Value)
+}
@@ -0,0 +1,108 @@
+package s3fifo
+
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/FelixSeptem/collections/lru"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestS3FIFO_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestS3FIFO_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestS3FIFO_Queues(t *testing.T) {
+	// small and main both take 2 items, the ghost queue remembers 2 keys
+	c := New[int, int](4, WithSmallRatio[int, int](0.5))
+	expect := func(keys []int, ghosts []int) {
+		t.Helper()
+		if diff := cmp.Diff(keys, c.Keys()); diff != "" {
+			t.Errorf("keys mismatch (-want +got):\n%s", diff)
+		}
+		var got []int
+		for v := c.ghost.Back(); v != nil; v = v.Prev() {
+			got = append(got, v.Value)
+		}
+		if diff := cmp.Diff(ghosts, got); diff != "" {
+			t.Errorf("ghosts mismatch (-want +got):\n%s", diff)
+		}
+	}
+	for i := 1; i <= 4; i++ {
+		c.Set(i, i)
+	}
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	// 1 is hit twice so it moves to main, 2 is hit once only so it's evicted
+	c.Set(5, 5)
+	expect([]int{3, 4, 5, 1}, []int{2})
+	// 2 is remembered by the ghost queue so it enters main
+	c.Set(2, 2)
+	expect([]int{4, 5, 1, 2}, []int{3})
+	c.Get(1)
+	c.Set(6, 6)
+	expect([]int{5, 6, 1, 2}, []int{3, 4})
+	// 5 moves to main which overflows, 1 is hit so it's reinserted and 2 is evicted
+	c.Get(5)
+	c.Get(5)
+	c.Set(7, 7)
+	expect([]int{6, 7, 5, 1}, []int{3, 4})
+	c.Remove(3)
+	expect([]int{6, 7, 5, 1}, []int{4})
+}
+
+func TestS3FIFO_Concurrent(t *testing.T) {
+	cachetest.RunConcurrent(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestS3FIFO_ScanResistance(t *testing.T) {
+	var trace []int
+	for i := range 10 {
+		trace = append(trace, cachetest.Zipf(int64(i), 1.01, 100000, 20000)...)
+		trace = append(trace, cachetest.Scan(1000000+i*5000, 5000)...)
+	}
+	got := cachetest.HitRatio(New[int, int](1000), trace)
+	base := cachetest.HitRatio(lru.New[int, int](1000), trace)
+	t.Logf("s3fifo: %.4f lru: %.4f", got, base)
+	if got <= base {
+		t.Errorf("expect S3-FIFO hit ratio higher than lru %.4f,got %.4f", base, got)
+	}
+}
+
+func BenchmarkS3FIFO_Set(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+}
+
+func BenchmarkS3FIFO_GetExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	c.Set(1, 1)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
+
+// BenchmarkS3FIFO_ParallelGet is compared with BenchmarkLRU_ParallelGet
+func BenchmarkS3FIFO_ParallelGet(b *testing.B) {
+	cachetest.ParallelGet(b, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
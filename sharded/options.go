@@ -0,0 +1,18 @@
+package sharded
+
+// Option configure the sharded cache created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithShards set the number of shards, it's rounded up to a power of two
+func WithShards[K comparable, V any](n int) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.count = n
+	}
+}
+
+// WithHash replace the default hash/maphash based hash used to pick the shard of a key
+func WithHash[K comparable, V any](hash func(key K) uint64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.hash = hash
+	}
+}
@@ -0,0 +1,204 @@
+// Package sharded implement a cache partitions keys over several independent caches, each guarded by its own lock,
+// so concurrent callers asking for different keys rarely contend
+package sharded
+
+import (
+	"context"
+	"hash/maphash"
+	"iter"
+
+	"github.com/FelixSeptem/collections/cache"
+)
+
+const (
+	// default shard count
+	Default_Shards = 16
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// Cache spreads its keys over shards by hash, each shard is a cache.Cache created by the given factory
+type Cache[K comparable, V any] struct {
+	shards []cache.Cache[K, V]
+	mask   uint64
+	hash   func(key K) uint64
+	count  int
+}
+
+// New return a cache holds about size items over shards created by newShard, e.g.
+//
+//	sharded.New[string, int](1 << 16, func(size int) cache.Cache[string, int] {
+//		return lru.New[string, int](size)
+//	})
+//
+// the shard count is rounded up to a power of two and lowered so that every shard holds at least one item,
+// the size is split evenly over shards rounded up so Cap may be slightly larger than size
+func New[K comparable, V any](size int, newShard func(size int) cache.Cache[K, V], opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		count: Default_Shards,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.hash == nil {
+		seed := maphash.MakeSeed()
+		c.hash = func(key K) uint64 {
+			return maphash.Comparable(seed, key)
+		}
+	}
+	n := 1
+	for n < c.count {
+		n <<= 1
+	}
+	for n > 1 && n > size {
+		n >>= 1
+	}
+	c.shards = make([]cache.Cache[K, V], n)
+	for i := range c.shards {
+		c.shards[i] = newShard((size + n - 1) / n)
+	}
+	c.mask = uint64(n - 1)
+	return c
+}
+
+// Shards return the number of shards
+func (c *Cache[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+// return the shard holds key
+func (c *Cache[K, V]) shard(key K) cache.Cache[K, V] {
+	return c.shards[c.hash(key)&c.mask]
+}
+
+// Add a new item into its shard, return if another item of the same shard has been evicted to make room for it
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	return c.shard(key).Set(key, value)
+}
+
+// Get value from its shard by key
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+// Cotains check if its shard contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	return c.shard(key).Contains(key)
+}
+
+// Remove the given key item return if the key has existed before
+func (c *Cache[K, V]) Remove(key K) bool {
+	return c.shard(key).Remove(key)
+}
+
+// Remove and return the oldest item of the first shard which isn't empty
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if every shard is empty
+// a shard is popped rather than checked first, so a shard emptied by another goroutine meanwhile is skipped instead
+// of ending the search, a shard isn't a cache.Popper can't tell an empty pop so it's still checked by Len first
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	for _, s := range c.shards {
+		if p, isPopper := s.(cache.Popper[K, V]); isPopper {
+			if key, value, ok = p.TryPopOldest(); ok {
+				return key, value, ok
+			}
+		} else if s.Len() > 0 {
+			key, value = s.PopOldest()
+			return key, value, true
+		}
+	}
+	return key, value, false
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	return c.shard(key).GetOrSet(key, value)
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into its shard
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.shard(key).GetOrLoad(ctx, key, loader)
+}
+
+// return all keys shard by shard, each in the order of its shard
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+// All iterate over the items shard by shard, each in the order of its shard, a shard's items are taken
+// when the iteration reaches it
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, s := range c.shards {
+			for k, v := range s.All() {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// return the total length of shards
+func (c *Cache[K, V]) Len() int {
+	var n int
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// Purge use to clear all shards
+func (c *Cache[K, V]) Purge() {
+	for _, s := range c.shards {
+		s.Purge()
+	}
+}
+
+// return the total capacity of shards
+func (c *Cache[K, V]) Cap() int {
+	var n int
+	for _, s := range c.shards {
+		n += s.Cap()
+	}
+	return n
+}
+
+// return the running information summed over shards
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	for _, s := range c.shards {
+		h, m, max, cur := s.Info()
+		hits += h
+		misses += m
+		maxSize += max
+		currentSize += cur
+	}
+	return hits, misses, maxSize, currentSize
+}
+
+// return the statistics summed over shards
+func (c *Cache[K, V]) Stats() cache.Stats {
+	var s cache.Stats
+	for _, shard := range c.shards {
+		s = s.Add(shard.Stats())
+	}
+	return s
+}
+
+// ResetStats set the statistics of all shards to zero
+func (c *Cache[K, V]) ResetStats() {
+	for _, s := range c.shards {
+		s.ResetStats()
+	}
+}
@@ -0,0 +1,182 @@
+package sharded
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/FelixSeptem/collections/arc"
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/FelixSeptem/collections/lfu"
+	"github.com/FelixSeptem/collections/lru"
+)
+
+var policies = []struct {
+	name     string
+	newShard func(size int) cache.Cache[int, int]
+}{
+	{"LRU", func(size int) cache.Cache[int, int] { return lru.New[int, int](size) }},
+	{"LFU", func(size int) cache.Cache[int, int] { return lfu.New[int, int](size) }},
+	{"ARC", func(size int) cache.Cache[int, int] { return arc.New[int, int](size) }},
+}
+
+func TestSharded_Conformance(t *testing.T) {
+	for _, p := range policies {
+		p := p
+		t.Run(p.name, func(t *testing.T) {
+			// a single shard behaves exactly like the cache it wraps
+			cachetest.Run(t, func(size int) cache.Cache[int, int] {
+				return New[int, int](size, p.newShard, WithShards[int, int](1))
+			})
+		})
+	}
+}
+
+func TestSharded_Shards(t *testing.T) {
+	tests := []struct {
+		size, shards, expect, cap int
+	}{
+		{1024, 0, 1, 1024},
+		{1024, 1, 1, 1024},
+		{1024, 5, 8, 1024},
+		{1024, 16, 16, 1024},
+		{100, 16, 16, 112},
+		{4, 16, 4, 4},
+		{3, 16, 2, 4},
+	}
+	for _, tt := range tests {
+		c := New[int, int](tt.size, policies[0].newShard, WithShards[int, int](tt.shards))
+		if n := c.Shards(); n != tt.expect {
+			t.Errorf("expect %d shards for %d,%d;got %d", tt.expect, tt.size, tt.shards, n)
+		}
+		if n := c.Cap(); n != tt.cap {
+			t.Errorf("expect cap %d for %d,%d;got %d", tt.cap, tt.size, tt.shards, n)
+		}
+	}
+	if n := New[int, int](1024, policies[0].newShard).Shards(); n != Default_Shards {
+		t.Errorf("expect %d,got %d", Default_Shards, n)
+	}
+}
+
+func TestSharded_TryPopOldest(t *testing.T) {
+	// shard by the key itself so the items are spread predictably
+	c := New[int, int](8, policies[0].newShard, WithShards[int, int](4), WithHash[int, int](func(key int) uint64 {
+		return uint64(key)
+	}))
+	c.Set(0, 0)
+	c.Set(3, 30)
+	if k, v, ok := c.TryPopOldest(); k != 0 || v != 0 || !ok {
+		t.Errorf("expect 0,0,true;got %v,%v,%v", k, v, ok)
+	}
+	// the empty shards are skipped
+	if k, v, ok := c.TryPopOldest(); k != 3 || v != 30 || !ok {
+		t.Errorf("expect 3,30,true;got %v,%v,%v", k, v, ok)
+	}
+	if _, _, ok := c.TryPopOldest(); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+
+	// every item is popped exactly once by concurrent callers
+	c = New[int, int](1<<13, policies[0].newShard)
+	for i := 0; i < 1<<12; i++ {
+		c.Set(i, i)
+	}
+	n := c.Len()
+	var (
+		lock   sync.Mutex
+		popped = make(map[int]int)
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				k, _, ok := c.TryPopOldest()
+				if !ok {
+					return
+				}
+				lock.Lock()
+				popped[k]++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(popped) != n || c.Len() != 0 {
+		t.Errorf("expect %d items popped,got %d with %d left", n, len(popped), c.Len())
+	}
+	for k, times := range popped {
+		if times != 1 {
+			t.Errorf("expect %d popped once,got %d", k, times)
+		}
+	}
+}
+
+func TestSharded_Aggregate(t *testing.T) {
+	// shard by the key itself so the items are spread predictably
+	c := New[int, int](8, policies[0].newShard, WithShards[int, int](4), WithHash[int, int](func(key int) uint64 {
+		return uint64(key)
+	}))
+	for i := 0; i < 8; i++ {
+		c.Set(i, i*10)
+	}
+	if l := c.Len(); l != 8 {
+		t.Errorf("expect 8,got %d", l)
+	}
+	if keys := fmt.Sprint(c.Keys()); keys != "[0 4 1 5 2 6 3 7]" {
+		t.Errorf("expect [0 4 1 5 2 6 3 7],got %s", keys)
+	}
+	// the shard of 0 and 4 is full
+	if evicted := c.Set(8, 80); !evicted || c.Contains(0) || !c.Contains(1) {
+		t.Errorf("expect 0 evicted from its shard only,got %v with %v", evicted, c.Keys())
+	}
+	c.Get(8)
+	c.Get(9)
+	if hits, misses, maxSize, currentSize := c.Info(); hits != 1 || misses != 1 || maxSize != 8 || currentSize != 8 {
+		t.Errorf("expect 1,1,8,8;got %d %d %d %d", hits, misses, maxSize, currentSize)
+	}
+	if k, v := c.PopOldest(); k != 4 || v != 40 {
+		t.Errorf("expect 4,40;got %v,%v", k, v)
+	}
+	c.Purge()
+	if l := c.Len(); l != 0 {
+		t.Errorf("expect 0,got %d", l)
+	}
+	if k, v := c.PopOldest(); k != 0 || v != 0 {
+		t.Errorf("expect 0,0;got %v,%v", k, v)
+	}
+}
+
+// BenchmarkSharded_Parallel compare the throughput of every policy unsharded and sharded under a mixed load
+// of 90% Get and 10% Set from GOMAXPROCS goroutines
+func BenchmarkSharded_Parallel(b *testing.B) {
+	const size = 1 << 16
+	for _, p := range policies {
+		for _, shards := range []int{0, Default_Shards} {
+			name, c := "unsharded", p.newShard(size)
+			if shards > 0 {
+				name = fmt.Sprintf("shards=%d", shards)
+				c = New[int, int](size, p.newShard, WithShards[int, int](shards))
+			}
+			for i := 0; i < size; i++ {
+				c.Set(i, i)
+			}
+			b.Run(p.name+"/"+name, func(b *testing.B) {
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						key := r.Intn(size * 2)
+						if r.Intn(10) == 0 {
+							c.Set(key, key)
+						} else {
+							c.Get(key)
+						}
+					}
+				})
+			})
+		}
+	}
+}
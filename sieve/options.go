@@ -0,0 +1,13 @@
+package sieve
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the SIEVE created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the SIEVE except the ones returned by PopOldest
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.evicted.SetCallback(fn)
+	}
+}
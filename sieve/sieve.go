@@ -0,0 +1,313 @@
+// Package sieve implement SIEVE as described in "SIEVE is Simpler than LRU: an Efficient Turn-Key Eviction Algorithm
+// for Web Caches" by Yazhuo Zhang, Juncheng Yang, Yao Yue, Ymir Vigfusson and K. V. Rashmi
+// https://www.usenix.org/conference/nsdi24/presentation/zhang-yazhuo
+// items are kept in a FIFO queue and a hit only marks the item visited, so Get takes the read lock only,
+// a hand moves from the oldest item to the newest clearing the marks and evicts the first unmarked item it meets
+package sieve
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default SIEVE size
+	Default_SIEVE_Size = 1024
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// Cache implements a thread safe fixed size SIEVE cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	// queue hold items from the newest at front to the oldest at back, hand is the item the next eviction
+	// starts from, the oldest one if it's nil
+	queue   *list.List[payload[K, V]]
+	hand    *list.Element[payload[K, V]]
+	items   map[K]*list.Element[payload[K, V]]
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+}
+
+// payload contains the value queue hold
+type payload[K comparable, V any] struct {
+	key   K
+	value V
+	// visited is set by hits holding the read lock only so it's accessed atomically
+	visited uint32
+}
+
+// New return a given size SIEVE holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_SIEVE_Size
+	}
+	c := &Cache[K, V]{
+		capacity: size,
+		queue:    list.New[payload[K, V]](),
+		items:    make(map[K]*list.Element[payload[K, V]]),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// return the SIEVE running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the SIEVE statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the SIEVE statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the SIEVE max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into SIEVE, the item the hand stops at is evicted if SIEVE is full
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	// key has exists, update it to new value
+	if v, ok := c.items[key]; ok {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		atomic.StoreUint32(&v.Value.visited, 1)
+		return false
+	}
+	if len(c.items) >= c.capacity {
+		c.evict(c.victim(), cache.EvictCapacity)
+		evicted = true
+	}
+	c.items[key] = c.queue.PushFront(payload[K, V]{key: key, value: value})
+	return evicted
+}
+
+// Get value from SIEVE by key, only the read lock is taken
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.get(key)
+}
+
+// get shall be called with the read or write lock held
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+	v, ok := c.items[key]
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	// skip the store if it's set already, so the cache line isn't written by every hit
+	if atomic.LoadUint32(&v.Value.visited) == 0 {
+		atomic.StoreUint32(&v.Value.visited, 1)
+	}
+	c.stats.Hit()
+	return v.Value.value, ok
+}
+
+// Cotains check if the SIEVE contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	v, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
+}
+
+// Remove the given key item return if the key has existed before
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	v, ok := c.items[key]
+	if ok {
+		c.evict(v, cache.EvictRemoved)
+	}
+	return ok
+}
+
+// Remove and return the item SIEVE would evict next, the hand moves to it clearing the marks on the way as an
+// eviction does, the item is handed to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the SIEVE is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if c.queue.Len() == 0 {
+		return key, value, false
+	}
+	v := c.victim()
+	c.removeItem(v)
+	return v.Value.key, v.Value.value, true
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into SIEVE
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the SIEVE hold in the order they would be evicted if no item were touched, the unvisited ones
+// from the hand to the newest and around from the oldest, then the visited ones in the same order
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for _, v := range c.order() {
+		keys = append(keys, v.Value.key)
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without marking them visited, the items are
+// taken when the iteration starts so it's safe to modify the SIEVE in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(c.items))
+	for _, v := range c.order() {
+		// copy the key and value only since visited may be written concurrently
+		items = append(items, payload[K, V]{key: v.Value.key, value: v.Value.value})
+	}
+	return items
+}
+
+// return the SIEVE length
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items)
+}
+
+// Purge use to clear all items in SIEVE, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for v := c.queue.Back(); v != nil; v = v.Prev() {
+		c.stats.Evict(cache.EvictPurged)
+		c.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+	}
+	c.queue.Init()
+	c.hand = nil
+	clear(c.items)
+}
+
+// return the items in the order of Keys, the lock must be held
+func (c *Cache[K, V]) order() []*list.Element[payload[K, V]] {
+	items := make([]*list.Element[payload[K, V]], 0, c.queue.Len())
+	start := c.hand
+	if start == nil {
+		start = c.queue.Back()
+	}
+	for _, visited := range []uint32{0, 1} {
+		v := start
+		for range c.queue.Len() {
+			if atomic.LoadUint32(&v.Value.visited) == visited {
+				items = append(items, v)
+			}
+			v = c.next(v)
+		}
+	}
+	return items
+}
+
+// victim move the hand to the item to be evicted and return it, the visited items on the way are unmarked,
+// SIEVE shall not be empty
+func (c *Cache[K, V]) victim() *list.Element[payload[K, V]] {
+	v := c.hand
+	if v == nil {
+		v = c.queue.Back()
+	}
+	for atomic.LoadUint32(&v.Value.visited) == 1 {
+		atomic.StoreUint32(&v.Value.visited, 0)
+		v = c.next(v)
+	}
+	c.hand = v
+	return v
+}
+
+// return the item the hand moves to after e, the newer one or the oldest one if e is the newest
+func (c *Cache[K, V]) next(e *list.Element[payload[K, V]]) *list.Element[payload[K, V]] {
+	if v := e.Prev(); v != nil {
+		return v
+	}
+	return c.queue.Back()
+}
+
+// remove item from SIEVE and notify the eviction callback
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.removeItem(e)
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from SIEVE, the hand moves to the newer item if it points to the removed one
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	if c.hand == e {
+		c.hand = e.Prev()
+	}
+	c.queue.Remove(e)
+	delete(c.items, e.Value.key)
+}
@@ -0,0 +1,82 @@
+package sieve
+
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSIEVE_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestSIEVE_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestSIEVE_Hand(t *testing.T) {
+	c := New[int, int](3)
+	for i := 1; i <= 3; i++ {
+		c.Set(i, i)
+	}
+	c.Get(1)
+	c.Get(2)
+	if diff := cmp.Diff([]int{3, 1, 2}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the hand unmarks 1 and 2 then evicts 3, the newest one, so it goes around to the oldest
+	c.Set(4, 4)
+	if diff := cmp.Diff([]int{1, 2, 4}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	// the hand stays at 2 after evicting 1
+	c.Get(4)
+	c.Set(5, 5)
+	if diff := cmp.Diff([]int{2, 5, 4}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	if k, v := c.PopOldest(); k != 2 || v != 2 {
+		t.Errorf("expect 2,2;got %v,%v", k, v)
+	}
+	if diff := cmp.Diff([]int{5, 4}, c.Keys()); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSIEVE_Concurrent(t *testing.T) {
+	cachetest.RunConcurrent(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func BenchmarkSIEVE_Set(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+}
+
+func BenchmarkSIEVE_GetExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	c.Set(1, 1)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
+
+// BenchmarkSIEVE_ParallelGet is compared with BenchmarkLRU_ParallelGet
+func BenchmarkSIEVE_ParallelGet(b *testing.B) {
+	cachetest.ParallelGet(b, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
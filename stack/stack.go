@@ -2,8 +2,10 @@
 package stack
 
 import (
-	"container/list"
+	"iter"
 	"sync"
+
+	"github.com/FelixSeptem/collections/internal/list"
 )
 
 const (
@@ -11,25 +13,30 @@ const (
 )
 
 // a fixed size FIFO stack
-type Stack struct {
+type Stack[T any] struct {
 	lock     sync.RWMutex
 	capacity int
-	items    *list.List
+	items    *list.List[T]
+}
+
+// NewStack return a given size stack holds arbitrary items
+func NewStack(size int) *Stack[interface{}] {
+	return New[interface{}](size)
 }
 
-// NewStack return a given size stack
-func NewStack(size int) *Stack {
+// New return a given size stack holds items of type T
+func New[T any](size int) *Stack[T] {
 	if size <= 0 {
 		size = Default_Stack_Size
 	}
-	return &Stack{
+	return &Stack[T]{
 		capacity: size,
-		items:    list.New(),
+		items:    list.New[T](),
 	}
 }
 
 // Push a new item into stack
-func (s *Stack) Push(item interface{}) (isSuccess bool) {
+func (s *Stack[T]) Push(item T) (isSuccess bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	if s.items.Len() == s.capacity {
@@ -40,57 +47,103 @@ func (s *Stack) Push(item interface{}) (isSuccess bool) {
 }
 
 // Pop a item from stack
-func (s *Stack) Pop() interface{} {
+func (s *Stack[T]) Pop() (item T) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	if s.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return s.items.Remove(s.items.Front())
 }
 
 // return the stack length
-func (s *Stack) Len() int {
+func (s *Stack[T]) Len() int {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 	return s.items.Len()
 }
 
 // return the stack capacity
-func (s *Stack) Cap() int {
+func (s *Stack[T]) Cap() int {
 	return s.capacity
 }
 
 // Get the item at the top of stack but don't remove it from stack
-func (s *Stack) GetTop() interface{} {
+func (s *Stack[T]) GetTop() (item T) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	if s.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return s.items.Front().Value
 }
 
 // Get the item at the bottom of stack
-func (s *Stack) GetBottom() interface{} {
+func (s *Stack[T]) GetBottom() (item T) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	if s.items.Len() == 0 {
-		return nil
+		return item
 	}
 	return s.items.Back().Value
 }
 
 // Check if the stack is empty
-func (s *Stack) IsEmpty() bool {
+func (s *Stack[T]) IsEmpty() bool {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	return s.items.Len() == 0
 }
 
 // Check if the stack is full
-func (s *Stack) IsFull() bool {
+func (s *Stack[T]) IsFull() bool {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 	return s.items.Len() == s.capacity
 }
+
+// All iterate over the positions and items from the top to the bottom, the items are taken when the iteration starts
+// so it's safe to modify the stack in the loop
+func (s *Stack[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, item := range s.snapshot() {
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward iterate over the positions and items in the reverse order of All
+func (s *Stack[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		items := s.snapshot()
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(i, items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values iterate over the items in the order of All
+func (s *Stack[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range s.snapshot() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// return the items from the top to the bottom
+func (s *Stack[T]) snapshot() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	items := make([]T, 0, s.items.Len())
+	for e := s.items.Front(); e != nil; e = e.Next() {
+		items = append(items, e.Value)
+	}
+	return items
+}
@@ -1,6 +1,11 @@
 package stack
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestQueue_Cap(t *testing.T) {
 	q := NewStack(32)
@@ -88,6 +93,21 @@ func TestQueue_Pop(t *testing.T) {
 	}
 }
 
+func TestStack_Typed(t *testing.T) {
+	s := New[string](2)
+	if v := s.Pop(); v != "" {
+		t.Errorf("expect empty string,got %q", v)
+	}
+	s.Push("a")
+	s.Push("b")
+	if ok := s.Push("c"); ok {
+		t.Errorf("expect false,got %v", ok)
+	}
+	if v := s.GetTop(); v != "b" {
+		t.Errorf("expect b,got %q", v)
+	}
+}
+
 func BenchmarkQueue_Push(b *testing.B) {
 	b.StopTimer()
 	q := NewStack(8096)
@@ -108,3 +128,34 @@ func BenchmarkQueue_Pop(b *testing.B) {
 		q.Pop()
 	}
 }
+
+func TestStack_All(t *testing.T) {
+	s := New[int](8)
+	for i := 3; i >= 1; i-- {
+		s.Push(i)
+	}
+	var positions []int
+	var items []int
+	for i, v := range s.All() {
+		positions = append(positions, i)
+		items = append(items, v)
+		// modify the stack in the loop is safe
+		s.Pop()
+	}
+	if !cmp.Equal(positions, []int{0, 1, 2}) || !cmp.Equal(items, []int{1, 2, 3}) {
+		t.Errorf("expect [0 1 2] [1 2 3],got %v %v", positions, items)
+	}
+	for i := 3; i >= 1; i-- {
+		s.Push(i)
+	}
+	var backward []int
+	for i, v := range s.Backward() {
+		if i == 0 {
+			break
+		}
+		backward = append(backward, v)
+	}
+	if values := slices.Collect(s.Values()); !cmp.Equal(values, []int{1, 2, 3}) || !cmp.Equal(backward, []int{3, 2}) {
+		t.Errorf("expect [1 2 3] [3 2],got %v %v", values, backward)
+	}
+}
@@ -0,0 +1,28 @@
+package twoq
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the 2Q created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the 2Q except the ones returned by PopOldest,
+// an item evicted to A1out is reported with cache.EvictCapacity since its value is dropped
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.evicted.SetCallback(fn)
+	}
+}
+
+// WithInRatio set the share of capacity A1in keeps before its items are evicted, Default_In_Ratio by default
+func WithInRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.inRatio = ratio
+	}
+}
+
+// WithOutRatio set the number of keys A1out remembers as a share of capacity, Default_Out_Ratio by default
+func WithOutRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.outRatio = ratio
+	}
+}
@@ -0,0 +1,338 @@
+// Package twoq implement the full version of 2Q as described in "2Q: A Low Overhead High Performance Buffer Management
+// Replacement Algorithm" by Theodore Johnson and Dennis Shasha https://www.vldb.org/conf/1994/P439.PDF
+// new items enter the FIFO A1in, the keys evicted from A1in are remembered by the FIFO A1out, only an item set again
+// while A1out remembers it enters the LRU Am, so items seen once in a scan never push the frequent ones out
+package twoq
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default 2Q size
+	Default_TwoQ_Size = 1024
+	// default share of capacity A1in keeps, the Kin of the paper
+	Default_In_Ratio = 0.25
+	// default number of keys A1out remembers as a share of capacity, the Kout of the paper
+	Default_Out_Ratio = 0.5
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// Cache implements a thread safe fixed size 2Q cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	inSize   int
+	outSize  int
+	inRatio  float64
+	outRatio float64
+
+	// in is A1in and am is Am, both from the newest at front to the oldest at back, items hold the items of both
+	in    *list.List[payload[K, V]]
+	am    *list.List[payload[K, V]]
+	items map[K]*list.Element[payload[K, V]]
+	// out is A1out from the newest at front to the oldest at back
+	out    *list.List[K]
+	ghosts map[K]*list.Element[K]
+
+	stats   cache.Recorder
+	evicted evict.Notifier[K, V]
+	loads   singleflight.Group[K, V]
+}
+
+// payload contains the value A1in and Am hold
+type payload[K comparable, V any] struct {
+	key   K
+	value V
+	// frequent tell if the item is in Am
+	frequent bool
+}
+
+// New return a given size 2Q holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_TwoQ_Size
+	}
+	c := &Cache[K, V]{
+		capacity: size,
+		inRatio:  Default_In_Ratio,
+		outRatio: Default_Out_Ratio,
+		in:       list.New[payload[K, V]](),
+		am:       list.New[payload[K, V]](),
+		items:    make(map[K]*list.Element[payload[K, V]]),
+		out:      list.New[K](),
+		ghosts:   make(map[K]*list.Element[K]),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.inSize = min(max(1, int(float64(size)*c.inRatio)), size)
+	c.outSize = max(0, int(float64(size)*c.outRatio))
+	return c
+}
+
+// return the 2Q running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the 2Q statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the 2Q statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the 2Q max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into 2Q, into Am if A1out remembers the key otherwise into A1in
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	// key has exists, update it to new value
+	if v, ok := c.items[key]; ok {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		c.touch(v)
+		return false
+	}
+	if g, ok := c.ghosts[key]; ok {
+		c.removeGhost(g)
+		evicted = c.reclaim()
+		c.items[key] = c.am.PushFront(payload[K, V]{key: key, value: value, frequent: true})
+		return evicted
+	}
+	evicted = c.reclaim()
+	c.items[key] = c.in.PushFront(payload[K, V]{key: key, value: value})
+	return evicted
+}
+
+// Get value from 2Q by key
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+	v, ok := c.items[key]
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	c.touch(v)
+	c.stats.Hit()
+	return v.Value.value, ok
+}
+
+// Cotains check if the 2Q contains the given key
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	v, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
+}
+
+// Remove the item from cache by key, a key only remembered by A1out is forgotten but reported as not existed
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.items[key]; ok {
+		c.evict(v, cache.EvictRemoved)
+		return true
+	}
+	if g, ok := c.ghosts[key]; ok {
+		c.removeGhost(g)
+	}
+	return false
+}
+
+// Remove and return the oldest item of the queue 2Q would reclaim next
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the 2Q is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		if v := l.Back(); v != nil {
+			c.removeItem(v)
+			return v.Value.key, v.Value.value, true
+		}
+	}
+	return key, value, false
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into 2Q
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the 2Q hold, the ones of the queue reclaimed next first, each queue from the oldest
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			keys = append(keys, v.Value.key)
+		}
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without updating their recency, the items are
+// taken when the iteration starts so it's safe to modify the 2Q in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			items = append(items, v.Value)
+		}
+	}
+	return items
+}
+
+// return the 2Q length
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items)
+}
+
+// Purge use to clear all items and the keys A1out remembers in 2Q, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		for v := l.Back(); v != nil; v = v.Prev() {
+			c.stats.Evict(cache.EvictPurged)
+			c.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+		}
+		l.Init()
+	}
+	c.out.Init()
+	clear(c.items)
+	clear(c.ghosts)
+}
+
+// return A1in and Am, the one reclaim takes from first
+func (c *Cache[K, V]) lists() []*list.List[payload[K, V]] {
+	if c.in.Len() > c.inSize || c.am.Len() == 0 {
+		return []*list.List[payload[K, V]]{c.in, c.am}
+	}
+	return []*list.List[payload[K, V]]{c.am, c.in}
+}
+
+// touch move a hit item of Am to the front, an item of A1in stays where it is as the paper does, because a burst
+// of requests to a new item is usually correlated and tells nothing about its future use
+func (c *Cache[K, V]) touch(e *list.Element[payload[K, V]]) {
+	if e.Value.frequent {
+		c.am.MoveToFront(e)
+	}
+}
+
+// reclaim evict an item to make room for a new one if 2Q is full, the oldest item of A1in if A1in exceeds its size
+// and remember its key in A1out, otherwise the least recently used item of Am
+func (c *Cache[K, V]) reclaim() (evicted bool) {
+	if len(c.items) < c.capacity {
+		return false
+	}
+	if c.in.Len() <= c.inSize && c.am.Len() > 0 {
+		c.evict(c.am.Back(), cache.EvictCapacity)
+		return true
+	}
+	v := c.in.Back()
+	c.evict(v, cache.EvictCapacity)
+	c.ghosts[v.Value.key] = c.out.PushFront(v.Value.key)
+	if c.out.Len() > c.outSize {
+		c.removeGhost(c.out.Back())
+	}
+	return true
+}
+
+// remove item from 2Q and notify the eviction callback
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.removeItem(e)
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from A1in or Am
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	if e.Value.frequent {
+		c.am.Remove(e)
+	} else {
+		c.in.Remove(e)
+	}
+	delete(c.items, e.Value.key)
+}
+
+// remove key from A1out
+func (c *Cache[K, V]) removeGhost(e *list.Element[K]) {
+	c.out.Remove(e)
+	delete(c.ghosts, e.Value)
+}
@@ -0,0 +1,105 @@
+package twoq
+
+import (
+	"testing"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/cache/cachetest"
+	"github.com/FelixSeptem/collections/lru"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTwoQ_Conformance(t *testing.T) {
+	cachetest.Run(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestTwoQ_OnEvict(t *testing.T) {
+	cachetest.RunOnEvict(t, func(size int, onEvict cache.EvictCallback[int, int]) cache.Cache[int, int] {
+		return New[int, int](size, WithOnEvict(onEvict))
+	})
+}
+
+func TestTwoQ_Concurrent(t *testing.T) {
+	cachetest.RunConcurrent(t, func(size int) cache.Cache[int, int] {
+		return New[int, int](size)
+	})
+}
+
+func TestTwoQ_Queues(t *testing.T) {
+	// Kin and Kout are both 2
+	c := New[int, int](4, WithInRatio[int, int](0.5), WithOutRatio[int, int](0.5))
+	expect := func(keys []int, ghosts []int) {
+		t.Helper()
+		if diff := cmp.Diff(keys, c.Keys()); diff != "" {
+			t.Errorf("keys mismatch (-want +got):\n%s", diff)
+		}
+		var got []int
+		for v := c.out.Back(); v != nil; v = v.Prev() {
+			got = append(got, v.Value)
+		}
+		if diff := cmp.Diff(ghosts, got); diff != "" {
+			t.Errorf("ghosts mismatch (-want +got):\n%s", diff)
+		}
+	}
+	for i := 1; i <= 5; i++ {
+		c.Set(i, i)
+	}
+	expect([]int{2, 3, 4, 5}, []int{1})
+	// 1 is remembered by A1out so it enters Am
+	c.Set(1, 1)
+	expect([]int{3, 4, 5, 1}, []int{2})
+	// a hit in A1in doesn't move the item
+	c.Get(3)
+	c.Set(2, 2)
+	expect([]int{1, 2, 4, 5}, []int{3})
+	// A1in is within Kin so the least recently used item of Am is evicted and forgotten
+	c.Get(1)
+	c.Set(6, 6)
+	expect([]int{4, 5, 6, 1}, []int{3})
+	c.Remove(3)
+	expect([]int{4, 5, 6, 1}, nil)
+}
+
+func TestTwoQ_ScanResistance(t *testing.T) {
+	var trace []int
+	for i := range 10 {
+		trace = append(trace, cachetest.Zipf(int64(i), 1.01, 100000, 20000)...)
+		trace = append(trace, cachetest.Scan(1000000+i*5000, 5000)...)
+	}
+	got := cachetest.HitRatio(New[int, int](1000), trace)
+	base := cachetest.HitRatio(lru.New[int, int](1000), trace)
+	t.Logf("2q: %.4f lru: %.4f", got, base)
+	if got <= base {
+		t.Errorf("expect 2Q hit ratio higher than lru %.4f,got %.4f", base, got)
+	}
+}
+
+func BenchmarkTwoQ_Set(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(i, i)
+	}
+}
+
+func BenchmarkTwoQ_GetExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	c.Set(1, 1)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
+
+func BenchmarkTwoQ_GetNotExist(b *testing.B) {
+	b.StopTimer()
+	c := New[int, int](8096)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get(1)
+	}
+}
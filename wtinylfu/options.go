@@ -0,0 +1,29 @@
+package wtinylfu
+
+import "github.com/FelixSeptem/collections/cache"
+
+// Option configure the W-TinyLFU created by New
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithOnEvict register a callback invoked for every item leaving the W-TinyLFU except the ones returned by PopOldest,
+// a new item rejected by the admission policy is reported with cache.EvictCapacity
+func WithOnEvict[K comparable, V any](fn cache.EvictCallback[K, V]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.evicted.SetCallback(fn)
+	}
+}
+
+// WithWindowRatio set the share of capacity taken by the admission window, Default_Window_Ratio by default,
+// a larger window suits workloads with bursts of recency
+func WithWindowRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.windowRatio = ratio
+	}
+}
+
+// WithProtectedRatio set the share of the main region taken by the protected segment, Default_Protected_Ratio by default
+func WithProtectedRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.protectedRatio = ratio
+	}
+}
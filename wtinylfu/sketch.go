@@ -0,0 +1,95 @@
+package wtinylfu
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+const (
+	// the number of rows of sketch, every key is counted once in each row
+	depth = 4
+	// counters saturate at 15 as the 4 bit counters of the paper
+	maxCount = 15
+	// counters are halved after sampleFactor times capacity keys are counted
+	sampleFactor = 10
+	// a row has widthFactor times capacity counters at least, so few keys of the sample share a counter
+	widthFactor = 4
+)
+
+// sketch is a count-min sketch estimates how often a key has been seen recently, all counters are halved
+// periodically so the history fades out as described in "TinyLFU: A Highly Efficient Cache Admission Policy"
+type sketch[K comparable] struct {
+	seed  maphash.Seed
+	table []uint8
+	// mask select a counter in a row, the width of rows is a power of two
+	mask      uint64
+	additions int
+	sample    int
+}
+
+// newSketch return a sketch for a cache of size
+func newSketch[K comparable](size int) *sketch[K] {
+	width := uint64(1) << bits.Len(uint(max(widthFactor*size, 16)-1))
+	return &sketch[K]{
+		seed:   maphash.MakeSeed(),
+		table:  make([]uint8, depth*width),
+		mask:   width - 1,
+		sample: sampleFactor * size,
+	}
+}
+
+// increment count the key once, only the least counters of key are incremented as the conservative update does
+// so keys sharing counters overestimate each other less, halve all counters when the sample is full
+func (s *sketch[K]) increment(key K) {
+	h := maphash.Comparable(s.seed, key)
+	var counters [depth]*uint8
+	least := uint8(maxCount)
+	for i := range counters {
+		counters[i] = &s.table[s.index(h, i)]
+		least = min(least, *counters[i])
+	}
+	if least < maxCount {
+		for _, c := range counters {
+			if *c == least {
+				*c++
+			}
+		}
+	}
+	s.additions++
+	if s.additions >= s.sample {
+		s.age()
+	}
+}
+
+// estimate return the least counter of key among the rows
+func (s *sketch[K]) estimate(key K) uint8 {
+	h := maphash.Comparable(s.seed, key)
+	count := uint8(maxCount)
+	for i := range depth {
+		count = min(count, s.table[s.index(h, i)])
+	}
+	return count
+}
+
+// age halve all counters
+func (s *sketch[K]) age() {
+	for i := range s.table {
+		s.table[i] >>= 1
+	}
+	s.additions /= 2
+}
+
+// reset set all counters to zero
+func (s *sketch[K]) reset() {
+	clear(s.table)
+	s.additions = 0
+}
+
+// return the index of the counter of hash h in row i, h is remixed for every row so the rows are independent
+func (s *sketch[K]) index(h uint64, i int) uint64 {
+	h += uint64(i+1) * 0x9e3779b97f4a7c15
+	h = (h ^ h>>30) * 0xbf58476d1ce4e5b9
+	h = (h ^ h>>27) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return uint64(i)*(s.mask+1) + h&s.mask
+}
@@ -0,0 +1,352 @@
+// Package wtinylfu implement Window-TinyLFU as described in "TinyLFU: A Highly Efficient Cache Admission Policy"
+// by Gil Einziger, Roy Friedman and Ben Manes https://arxiv.org/pdf/1512.00727.pdf
+// new items enter a small LRU window, an item leaving the window is admitted into the segmented LRU main region
+// only if it has been seen more often recently than the item main would evict, so scans and one-hit wonders
+// can't flush the frequently used items out
+package wtinylfu
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/FelixSeptem/collections/cache"
+	"github.com/FelixSeptem/collections/internal/evict"
+	"github.com/FelixSeptem/collections/internal/list"
+	"github.com/FelixSeptem/collections/internal/singleflight"
+)
+
+const (
+	// default W-TinyLFU size
+	Default_WTinyLFU_Size = 1024
+	// default share of capacity taken by the admission window
+	Default_Window_Ratio = 0.01
+	// default share of the main region taken by the protected segment
+	Default_Protected_Ratio = 0.8
+)
+
+var (
+	_ cache.Cache[int, int]  = (*Cache[int, int])(nil)
+	_ cache.Popper[int, int] = (*Cache[int, int])(nil)
+)
+
+// segment tell which list an item is in
+type segment uint8
+
+const (
+	window segment = iota
+	probation
+	protected
+)
+
+// Cache implements a thread safe fixed size W-TinyLFU cache
+type Cache[K comparable, V any] struct {
+	lock     sync.RWMutex
+	capacity int
+	// the max length of window and protected, probation takes the rest of capacity
+	windowSize     int
+	protectedSize  int
+	windowRatio    float64
+	protectedRatio float64
+
+	// window, probation and protected all hold items from the least recently used at front to the most recently
+	// used at back, probation and protected together are the main region
+	window    *list.List[payload[K, V]]
+	probation *list.List[payload[K, V]]
+	protected *list.List[payload[K, V]]
+	items     map[K]*list.Element[payload[K, V]]
+	sketch    *sketch[K]
+	stats     cache.Recorder
+	evicted   evict.Notifier[K, V]
+	loads     singleflight.Group[K, V]
+}
+
+// payload contains the value the lists hold
+type payload[K comparable, V any] struct {
+	key     K
+	value   V
+	segment segment
+}
+
+// New return a given size W-TinyLFU holds keys of type K and values of type V
+func New[K comparable, V any](size int, opts ...Option[K, V]) *Cache[K, V] {
+	if size <= 0 {
+		size = Default_WTinyLFU_Size
+	}
+	c := &Cache[K, V]{
+		capacity:       size,
+		windowRatio:    Default_Window_Ratio,
+		protectedRatio: Default_Protected_Ratio,
+		window:         list.New[payload[K, V]](),
+		probation:      list.New[payload[K, V]](),
+		protected:      list.New[payload[K, V]](),
+		items:          make(map[K]*list.Element[payload[K, V]]),
+		sketch:         newSketch[K](size),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.windowSize = min(max(1, int(float64(size)*c.windowRatio)), size)
+	c.protectedSize = max(0, int(float64(size-c.windowSize)*min(c.protectedRatio, 1)))
+	return c
+}
+
+// return the W-TinyLFU running information
+func (c *Cache[K, V]) Info() (hits int, misses int, maxSize int, currentSize int) {
+	s := c.stats.Stats()
+	return int(s.Hits), int(s.Misses), c.capacity, c.Len()
+}
+
+// return a snapshot of the W-TinyLFU statistics
+func (c *Cache[K, V]) Stats() cache.Stats {
+	return c.stats.Stats()
+}
+
+// ResetStats set all the W-TinyLFU statistics to zero
+func (c *Cache[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// return the W-TinyLFU max capacity
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Add a new item into the window of W-TinyLFU, return if the window overflowed and the item it pushed out or
+// the item main would evict has been evicted
+func (c *Cache[K, V]) Set(key K, value V) (evicted bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) (evicted bool) {
+	c.stats.Set()
+	c.sketch.increment(key)
+	// key has exists, update it to new value
+	if v, ok := c.items[key]; ok {
+		c.stats.Evict(cache.EvictReplaced)
+		c.evicted.Add(key, v.Value.value, cache.EvictReplaced)
+		v.Value.value = value
+		c.touch(v)
+		return false
+	}
+	c.items[key] = c.window.PushBack(payload[K, V]{key: key, value: value, segment: window})
+	if c.window.Len() <= c.windowSize {
+		return false
+	}
+
+	// the window overflowed, its least recently used item moves to probation if there is room for it,
+	// otherwise it competes with the item main would evict and the less frequently seen one is evicted
+	candidate := c.window.Front()
+	if len(c.items) <= c.capacity {
+		c.move(candidate, c.probation, probation)
+		return false
+	}
+	victim := c.probation.Front()
+	if victim == nil {
+		victim = c.protected.Front()
+	}
+	if victim == nil || c.sketch.estimate(candidate.Value.key) <= c.sketch.estimate(victim.Value.key) {
+		c.evict(candidate, cache.EvictCapacity)
+		return true
+	}
+	c.evict(victim, cache.EvictCapacity)
+	c.move(candidate, c.probation, probation)
+	return true
+}
+
+// Get value from W-TinyLFU by key
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+	// misses are counted too, so a key requested often gets admitted once it's set
+	c.sketch.increment(key)
+	v, ok := c.items[key]
+	if !ok {
+		c.stats.Miss()
+		return value, ok
+	}
+	c.touch(v)
+	c.stats.Hit()
+	return v.Value.value, ok
+}
+
+// Cotains check if the W-TinyLFU contains the given key without counting it
+func (c *Cache[K, V]) Contains(key K) bool {
+	_, ok := c.peek(key)
+	return ok
+}
+
+// peek return the value of key without touching the item or the statistics
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	v, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	return v.Value.value, true
+}
+
+// Remove the given key item return if the key has existed before
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	v, ok := c.items[key]
+	if ok {
+		c.evict(v, cache.EvictRemoved)
+	}
+	return ok
+}
+
+// Remove and return the first item of Keys, the least recently used item of probation unless it's empty,
+// the item is handed to the caller so the eviction callback isn't invoked for it
+func (c *Cache[K, V]) PopOldest() (key K, value V) {
+	key, value, _ = c.TryPopOldest()
+	return key, value
+}
+
+// TryPopOldest remove and return the item PopOldest would, ok is false if the W-TinyLFU is empty
+func (c *Cache[K, V]) TryPopOldest() (key K, value V, ok bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		if v := l.Front(); v != nil {
+			c.removeItem(v)
+			return v.Value.key, v.Value.value, true
+		}
+	}
+	return key, value, false
+}
+
+// return the value if the key exist, otherwise update the key by given value similar with redis SETNX
+func (c *Cache[K, V]) GetOrSet(key K, value V) (newValue V, isGet bool) {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	if v, ok := c.get(key); ok {
+		return v, ok
+	}
+	c.set(key, value)
+	return value, false
+}
+
+// GetOrLoad return the value if the key exist, otherwise load it by loader and add it into W-TinyLFU
+// concurrent calls for the same key share a single loader run, each caller stops waiting once its ctx is done,
+// a failed load is not cached
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader cache.Loader[K, V]) (V, error) {
+	return c.loads.Load(ctx, key, c.Get, c.peek, c.Set, loader, &c.stats)
+}
+
+// return all keys the W-TinyLFU hold by segment, the ones in probation then protected then window all from the least
+// recently used, it isn't the eviction order, the next eviction takes the first of probation or the first of window
+// whichever has been seen less often, so it depends on the frequencies when the window overflows
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]K, 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Front(); v != nil; v = v.Next() {
+			keys = append(keys, v.Value.key)
+		}
+	}
+	return keys
+}
+
+// All iterate over the items in the order of Keys without updating their recency or frequency, the items are
+// taken when the iteration starts so it's safe to modify the W-TinyLFU in the loop
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, v := range c.snapshot() {
+			if !yield(v.key, v.value) {
+				return
+			}
+		}
+	}
+}
+
+// return the items in the order of Keys
+func (c *Cache[K, V]) snapshot() []payload[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	items := make([]payload[K, V], 0, len(c.items))
+	for _, l := range c.lists() {
+		for v := l.Front(); v != nil; v = v.Next() {
+			items = append(items, v.Value)
+		}
+	}
+	return items
+}
+
+// return the W-TinyLFU length
+func (c *Cache[K, V]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items)
+}
+
+// Purge use to clear all items and the frequency history in W-TinyLFU, the statistics are kept
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.evicted.Unlock(&c.lock)
+	for _, l := range c.lists() {
+		for v := l.Front(); v != nil; v = v.Next() {
+			c.stats.Evict(cache.EvictPurged)
+			c.evicted.Add(v.Value.key, v.Value.value, cache.EvictPurged)
+		}
+		l.Init()
+	}
+	clear(c.items)
+	c.sketch.reset()
+}
+
+// return the lists in the order of Keys
+func (c *Cache[K, V]) lists() []*list.List[payload[K, V]] {
+	return []*list.List[payload[K, V]]{c.probation, c.protected, c.window}
+}
+
+// touch move a hit item to the most recently used end of its segment, an item hit in probation is promoted
+// to protected which demotes the least recently used item of protected to probation if it's full
+func (c *Cache[K, V]) touch(e *list.Element[payload[K, V]]) {
+	switch e.Value.segment {
+	case window:
+		c.window.MoveToBack(e)
+	case protected:
+		c.protected.MoveToBack(e)
+	case probation:
+		c.move(e, c.protected, protected)
+		if c.protected.Len() > c.protectedSize {
+			c.move(c.protected.Front(), c.probation, probation)
+		}
+	}
+}
+
+// move item to the back of l
+func (c *Cache[K, V]) move(e *list.Element[payload[K, V]], l *list.List[payload[K, V]], s segment) {
+	e.Value.segment = s
+	l.PushBackElement(e)
+}
+
+// remove item from W-TinyLFU and notify the eviction callback
+func (c *Cache[K, V]) evict(e *list.Element[payload[K, V]], reason cache.EvictReason) {
+	c.removeItem(e)
+	c.stats.Evict(reason)
+	c.evicted.Add(e.Value.key, e.Value.value, reason)
+}
+
+// remove item from its segment
+func (c *Cache[K, V]) removeItem(e *list.Element[payload[K, V]]) {
+	switch e.Value.segment {
+	case window:
+		c.window.Remove(e)
+	case probation:
+		c.probation.Remove(e)
+	case protected:
+		c.protected.Remove(e)
+	}
+	delete(c.items, e.Value.key)
+}